@@ -0,0 +1,50 @@
+package glogi
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// argMismatchKey is the attr that replaces a dangling key in an odd argument list
+const argMismatchKey = "LOG_ARG_MISMATCH"
+
+// strictArgs drops records with a dangling key instead of annotating them
+var strictArgs bool
+
+// SetStrictArgs makes records with a dangling key (log.Info("msg", "key"))
+// be dropped and reported on stderr, instead of logged with a
+// LOG_ARG_MISMATCH=key attr. Useful in tests and CI to catch typos early.
+func SetStrictArgs(enabled bool) { strictArgs = enabled }
+
+// danglingKey returns the trailing key that has no value, following slog's
+// argument rules (a string key consumes the next arg, an Attr stands alone)
+func danglingKey(args []any) (string, bool) {
+	for i := 0; i < len(args); i++ {
+		switch k := args[i].(type) {
+		case slog.Attr:
+		case string:
+			if i == len(args)-1 {
+				return k, true
+			}
+			i++ // Skip the value
+		}
+	}
+	return "", false
+}
+
+// checkArgs handles a dangling key in args: it is replaced by a
+// LOG_ARG_MISMATCH attr naming it, or in strict mode the record is dropped
+// (ok is false) and reported on stderr. pc identifies the call site.
+func checkArgs(msg string, pc uintptr, args []any) ([]any, bool) {
+	key, found := danglingKey(args)
+	if !found {
+		return args, true
+	}
+	if strictArgs {
+		fmt.Fprintf(os.Stderr, "glogi: dropped %q at %s: key %q has no value\n", msg, sourceLocation(pc), key)
+		return nil, false
+	}
+	fixed := append(args[:len(args)-1:len(args)-1], slog.String(argMismatchKey, key))
+	return fixed, true
+}
@@ -0,0 +1,76 @@
+package glogi
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestDanglingKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []any
+		key   string
+		found bool
+	}{
+		{"empty", nil, "", false},
+		{"even", []any{"id", 1, "name", "x"}, "", false},
+		{"odd", []any{"id", 1, "name"}, "name", true},
+		{"single key", []any{"id"}, "id", true},
+		{"attr", []any{slog.Int("id", 1)}, "", false},
+		{"attr then key", []any{slog.Int("id", 1), "name"}, "name", true},
+		{"value that is a string", []any{"name", "id"}, "", false},
+	}
+	for _, tt := range tests {
+		key, found := danglingKey(tt.args)
+		if key != tt.key || found != tt.found {
+			t.Errorf("%s: danglingKey = %q, %v, want %q, %v", tt.name, key, found, tt.key, tt.found)
+		}
+	}
+}
+
+func TestArgMismatch(t *testing.T) {
+	tests := []struct {
+		name string
+		args []any
+		want string
+	}{
+		{"empty", nil, "] saved\n"},
+		{"even", []any{"id", 1}, "] saved id=1\n"},
+		{"odd", []any{"id", 1, "name"}, "] saved id=1 LOG_ARG_MISMATCH=name\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := captureOutput(t, "text")
+			Info("saved", tt.args...)
+			if !strings.HasSuffix(buf.String(), tt.want) {
+				t.Errorf("output = %q, want suffix %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestArgMismatchKeepsCallerArgs(t *testing.T) {
+	captureOutput(t, "text")
+	args := make([]any, 3, 4)
+	copy(args, []any{"id", 1, "name"})
+	Info("saved", args...)
+	if args[:4][3] != nil || args[2] != "name" {
+		t.Errorf("caller's args changed: %v", args[:4])
+	}
+}
+
+func TestStrictArgs(t *testing.T) {
+	buf := captureOutput(t, "text")
+	SetStrictArgs(true)
+	t.Cleanup(func() { SetStrictArgs(false) })
+
+	Info("dropped", "id")
+	Info("kept", "id", 1)
+	if strings.Contains(buf.String(), "dropped") {
+		t.Errorf("record with a dangling key was logged: %q", buf.String())
+	}
+	if !strings.Contains(buf.String(), "kept id=1") {
+		t.Errorf("output = %q", buf.String())
+	}
+}
@@ -0,0 +1,261 @@
+package glogi
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// asyncItem is a formatted record, or a flush marker when flushed is set
+type asyncItem struct {
+	b       []byte
+	level   slog.Level
+	flushed chan struct{}
+}
+
+// asyncWriter queues formatted records on a buffered channel and writes them
+// to out from a background goroutine. When the queue is full the oldest
+// record is dropped, so logging never blocks on a slow destination.
+// With a flush interval, records are batched and written once per interval
+// (or when the batch reaches maxBatchBytes) instead of one write per record,
+// unless out needs each record's level (levelWriter).
+type asyncWriter struct {
+	out      io.Writer
+	outMu    *sync.Mutex // Guards writes to out, shared with the handler that wrote to it before
+	ch       chan asyncItem
+	done     chan struct{}
+	dropped  atomic.Uint64
+	interval time.Duration
+
+	mu     sync.RWMutex // Guards closed against concurrent Write/close
+	closed bool
+
+	errMu sync.Mutex
+	err   error // First write error since the last flush
+}
+
+func newAsyncWriter(out io.Writer, outMu *sync.Mutex, bufSize int, interval time.Duration) *asyncWriter {
+	a := &asyncWriter{
+		out:      out,
+		outMu:    outMu,
+		ch:       make(chan asyncItem, bufSize),
+		done:     make(chan struct{}),
+		interval: interval,
+	}
+	go a.run()
+	return a
+}
+
+// run writes queued records until the queue is closed
+func (a *asyncWriter) run() {
+	defer close(a.done)
+	var tick <-chan time.Time
+	if _, leveled := a.out.(levelWriter); a.interval > 0 && !leveled {
+		t := time.NewTicker(a.interval)
+		defer t.Stop()
+		tick = t.C
+	}
+
+	var batch bytes.Buffer
+	drain := func() {
+		if batch.Len() > 0 {
+			a.write(LevelInfo, batch.Bytes())
+			batch.Reset()
+		}
+	}
+	for {
+		select {
+		case it, ok := <-a.ch:
+			switch {
+			case !ok:
+				drain()
+				return
+			case it.flushed != nil:
+				drain()
+				close(it.flushed)
+			case tick == nil:
+				a.write(it.level, it.b)
+			default:
+				batch.Write(it.b)
+				if batch.Len() >= maxBatchBytes {
+					drain()
+				}
+			}
+		case <-tick:
+			drain()
+		}
+	}
+}
+
+// write writes a record of level l to out and records the first error
+func (a *asyncWriter) write(l slog.Level, b []byte) {
+	if _, err := a.writeOut(l, b); err != nil {
+		reportWriteError(err)
+		a.errMu.Lock()
+		if a.err == nil {
+			a.err = err
+		}
+		a.errMu.Unlock()
+	}
+}
+
+// writeOut writes a record of level l to out under its shared lock
+func (a *asyncWriter) writeOut(l slog.Level, p []byte) (int, error) {
+	a.outMu.Lock()
+	defer a.outMu.Unlock()
+	if lw, ok := a.out.(levelWriter); ok {
+		return lw.writeLevel(l, p)
+	}
+	return a.out.Write(p)
+}
+
+// Write queues a copy of p as an INFO record
+func (a *asyncWriter) Write(p []byte) (int, error) {
+	return a.writeLevel(LevelInfo, p)
+}
+
+// writeLevel queues a copy of a record of level l. After close it writes to
+// out directly.
+func (a *asyncWriter) writeLevel(l slog.Level, p []byte) (int, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return a.writeOut(l, p)
+	}
+
+	it := asyncItem{b: append([]byte(nil), p...), level: l}
+	for {
+		select {
+		case a.ch <- it:
+			return len(p), nil
+		default:
+		}
+		// Queue full: drop the oldest record to make room
+		select {
+		case old := <-a.ch:
+			if old.flushed != nil {
+				close(old.flushed) // Everything queued before it is gone too
+			} else {
+				a.dropped.Add(1)
+			}
+		default:
+		}
+	}
+}
+
+// flush waits until every record queued so far has been written and returns
+// the first write error since the previous flush
+func (a *asyncWriter) flush() error {
+	a.mu.RLock()
+	if !a.closed {
+		marker := make(chan struct{})
+		a.ch <- asyncItem{flushed: marker}
+		a.mu.RUnlock()
+		<-marker
+	} else {
+		a.mu.RUnlock()
+	}
+
+	a.errMu.Lock()
+	defer a.errMu.Unlock()
+	err := a.err
+	a.err = nil
+	return err
+}
+
+// close drains the queue and stops the background goroutine
+func (a *asyncWriter) close() error {
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.ch)
+	}
+	a.mu.Unlock()
+	<-a.done
+
+	a.errMu.Lock()
+	defer a.errMu.Unlock()
+	return a.err
+}
+
+var (
+	asyncMu       sync.Mutex
+	asyncOut      *asyncWriter  // Active async writer of the global logger, if any
+	flushInterval time.Duration // Batching interval of the async writer, 0 writes each record
+)
+
+// SetAsync switches the global logger to asynchronous output: records are
+// formatted on the calling goroutine, queued in a buffer of bufSize records and
+// written by a background goroutine, so logging does not block on a slow
+// terminal or pipe. When the buffer is full the oldest queued record is dropped
+// (see AsyncDropped). SetAsync(0) restores synchronous output.
+//
+// Call Flush to wait for queued records and Close before exiting; Fatal and
+// PanicLog flush automatically. Call SetOutput before SetAsync: SetOutput
+// replaces the async writer with a synchronous one.
+func SetAsync(bufSize int) {
+	ensureInit()
+	h, ok := primaryHandler().(writerHandler)
+	if !ok {
+		return
+	}
+
+	asyncMu.Lock()
+	defer asyncMu.Unlock()
+	if asyncOut != nil {
+		_ = asyncOut.close()
+		h.setOutput(asyncOut.out)
+		asyncOut = nil
+	}
+	if bufSize <= 0 {
+		return
+	}
+
+	out := h.output()
+	mu := writeLock(out)
+	if ref, ok := out.(*outputRef); ok {
+		box := ref.p.Load()
+		out, mu = box.w, box.mu // Wrap the destination, not the handler's reference to it
+	}
+	asyncOut = newAsyncWriter(out, mu, bufSize, flushInterval)
+	h.setOutput(asyncOut)
+}
+
+// SetFlushInterval makes the async writer (SetAsync) batch records and write
+// them at most every d, trading up to d of latency for fewer writes. Batches
+// are also written when they reach 1 MiB, on Flush and on Close, which stops
+// the ticker. 0 (the default) writes each record as soon as it is dequeued.
+// An active async writer is restarted with the new interval.
+func SetFlushInterval(d time.Duration) {
+	asyncMu.Lock()
+	flushInterval = d
+	bufSize := 0
+	if asyncOut != nil {
+		bufSize = cap(asyncOut.ch)
+	}
+	asyncMu.Unlock()
+	if bufSize > 0 {
+		SetAsync(bufSize)
+	}
+}
+
+// asyncEnabled reports whether the global logger writes asynchronously
+func asyncEnabled() bool {
+	asyncMu.Lock()
+	defer asyncMu.Unlock()
+	return asyncOut != nil
+}
+
+// AsyncDropped returns how many records the async writer has dropped because
+// its buffer was full
+func AsyncDropped() uint64 {
+	asyncMu.Lock()
+	defer asyncMu.Unlock()
+	if asyncOut == nil {
+		return 0
+	}
+	return asyncOut.dropped.Load()
+}
@@ -0,0 +1,111 @@
+package glogi
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a buffer that can be read while the async writer writes to it
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestFlushIntervalWritesLowVolume(t *testing.T) {
+	const interval = 50 * time.Millisecond
+	captureOutput(t, "text")
+	out := &syncBuffer{}
+	SetOutput(out)
+	SetFlushInterval(interval)
+	SetAsync(16)
+	t.Cleanup(func() { SetFlushInterval(0) })
+
+	start := time.Now()
+	Info("low volume")
+	// Allow for scheduling delays, but far less than a full-buffer flush would take
+	deadline := start.Add(10 * interval)
+	for !strings.Contains(out.String(), "low volume") {
+		if time.Now().After(deadline) {
+			t.Fatalf("record not written %s after logging (interval %s)", time.Since(start), interval)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestCloseStopsFlushTicker(t *testing.T) {
+	captureOutput(t, "text")
+	out := &syncBuffer{}
+	SetOutput(out)
+	SetFlushInterval(time.Hour)
+	SetAsync(16)
+	t.Cleanup(func() { SetFlushInterval(0) })
+
+	asyncMu.Lock()
+	a := asyncOut
+	asyncMu.Unlock()
+	Info("pending")
+	time.Sleep(20 * time.Millisecond)
+	if strings.Contains(out.String(), "pending") {
+		t.Fatal("record written before the flush interval")
+	}
+	if err := Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-a.done:
+	case <-time.After(time.Second):
+		t.Fatal("async writer still running after Close")
+	}
+	if !strings.Contains(out.String(), "pending") {
+		t.Errorf("Close didn't write the pending batch: %q", out.String())
+	}
+}
+
+func TestSetOutputAfterSetAsync(t *testing.T) {
+	captureOutput(t, "text")
+	old, next := &syncBuffer{}, &syncBuffer{}
+	SetOutput(old)
+	SetAsync(16)
+	asyncMu.Lock()
+	a := asyncOut
+	asyncMu.Unlock()
+
+	Info("queued")
+	SetOutput(next)
+	Info("after SetOutput")
+	if err := Close(); err != nil {
+		t.Fatal(err)
+	}
+	if out := primaryHandler().(writerHandler).output().(*outputRef).Load(); out != next {
+		t.Errorf("Close restored the output to %T", out)
+	}
+	select {
+	case <-a.done:
+	case <-time.After(time.Second):
+		t.Fatal("async writer still running after SetOutput")
+	}
+	if !strings.Contains(old.String(), "queued") || strings.Contains(old.String(), "after") {
+		t.Errorf("old output = %q", old.String())
+	}
+	if !strings.Contains(next.String(), "after SetOutput") {
+		t.Errorf("new output = %q", next.String())
+	}
+	if asyncEnabled() {
+		t.Error("async output still enabled")
+	}
+}
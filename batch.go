@@ -0,0 +1,81 @@
+package glogi
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+)
+
+// maxBatchBytes caps how much a Batch buffers before writing.
+// Batches larger than this are written in several chunks; each chunk is
+// still a single Write, but other goroutines may interleave between chunks.
+const maxBatchBytes = 1 << 20 // 1 MiB
+
+// writerHandler is a glogi handler whose destination writer can be replaced
+type writerHandler interface {
+	slog.Handler
+	withWriter(w io.Writer) slog.Handler
+	output() io.Writer // Destination with write locking
+	setOutput(w io.Writer)
+	setErrorOutput(w io.Writer) // nil disables split output
+	addOutput(w io.Writer, colored bool)
+}
+
+// batchWriter accumulates formatted records and writes them to out in one call
+type batchWriter struct {
+	buf bytes.Buffer
+	out io.Writer
+	err error
+}
+
+func (b *batchWriter) Write(p []byte) (int, error) {
+	// Write out what we have before the buffer grows past the cap
+	if b.buf.Len() > 0 && b.buf.Len()+len(p) > maxBatchBytes {
+		b.flush()
+	}
+	return b.buf.Write(p)
+}
+
+// flush writes the buffered records with a single Write
+func (b *batchWriter) flush() {
+	if b.buf.Len() == 0 {
+		return
+	}
+	if _, err := b.out.Write(b.buf.Bytes()); err != nil && b.err == nil {
+		b.err = err
+	}
+	b.buf.Reset()
+}
+
+// Batch buffers every record logged through l inside fn and writes them with a
+// single Write when fn returns, so the lines stay together even when other
+// goroutines log concurrently. Useful for tables, reports and grouped diagnostics.
+//
+//	log.Batch(func(l *log.Logger) {
+//	    for _, row := range rows {
+//	        l.Info("row", "name", row.Name, "total", row.Total)
+//	    }
+//	})
+//
+// Batches larger than 1 MiB are written in 1 MiB chunks instead of being held
+// entirely in memory; contiguity is then guaranteed per chunk only.
+// Returns the first write error, if any.
+func Batch(fn func(l *Logger)) error {
+	ensureInit()
+	h, ok := primaryHandler().(writerHandler)
+	if !ok {
+		// Unknown handler: no buffering possible, log directly
+		fn(&Logger{sl: logger()})
+		return nil
+	}
+
+	bw := &batchWriter{out: h.output()}
+	bh := h.withWriter(bw)
+	if splitOutput {
+		// WARN and above still go straight to the error output
+		bh.(writerHandler).setErrorOutput(errorOutput)
+	}
+	fn(&Logger{sl: slog.New(bh)})
+	bw.flush()
+	return bw.err
+}
@@ -0,0 +1,49 @@
+package glogi
+
+import "log/slog"
+
+// Keys of the old and new values of a Change attr in structured output
+const (
+	ChangeOldKey = "old"
+	ChangeNewKey = "new"
+)
+
+// Arrows between the old and new values of a Change attr in the text format
+const (
+	changeArrow      = " → "
+	changeArrowPlain = " -> "
+)
+
+// change is the value of a Change attr. It resolves to an old/new group, so
+// handlers that don't know it log {"old":...,"new":...}.
+type change struct {
+	old, new any
+}
+
+// LogValue implements slog.LogValuer
+func (c change) LogValue() slog.Value {
+	return slog.GroupValue(slog.Any(ChangeOldKey, c.old), slog.Any(ChangeNewKey, c.new))
+}
+
+// Change returns an attr for a value that changed from old to new, e.g. in
+// config-change audit logs. The text format renders it like a diff, the old
+// value in red and the new one in green:
+//
+//	log.Info("config updated", log.Change("timeout", "30s", "60s")) // ... timeout=30s → 60s
+//
+// Without colors the arrow is "->". Logfmt writes timeout.old=30s
+// timeout.new=60s, the structured handlers {"timeout":{"old":"30s","new":"60s"}}.
+func Change(key string, old, new any) slog.Attr {
+	return slog.Any(key, change{old: old, new: new})
+}
+
+// changeField renders a Change attr for the text format: value holds the old
+// value and newValue the new one, each formatted like any attr value
+func changeField(key string, c change) attrField {
+	return attrField{
+		key:      key,
+		value:    formatValue(slog.AnyValue(c.old).Resolve()),
+		newValue: formatValue(slog.AnyValue(c.new).Resolve()),
+		change:   true,
+	}
+}
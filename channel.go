@@ -0,0 +1,148 @@
+package glogi
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+// channelSink is shared by a ChannelHandler and the handlers derived from it
+type channelSink struct {
+	ch      chan<- Record
+	block   atomic.Bool   // Wait for room instead of dropping
+	dropped atomic.Uint64 // Records dropped because the channel was full
+}
+
+// ChannelHandler implements slog.Handler and sends each record as a Record
+// (Time, Level, Message, Source, Attrs) to a channel, e.g. to feed a live log
+// viewer or to assert on records in tests without parsing formatted text.
+// By default a record is dropped when the channel is full, so a slow consumer
+// never blocks logging; see SetBlocking and Dropped.
+//
+//	ch := make(chan log.Record, 256)
+//	logger := slog.New(log.NewChannelHandler(ch, nil))
+//	go func() {
+//	    for rec := range ch {
+//	        hub.Broadcast(rec)
+//	    }
+//	}()
+type ChannelHandler struct {
+	level  *slog.LevelVar
+	sink   *channelSink
+	attrs  []groupedAttr
+	groups []string
+}
+
+// NewChannelHandler creates a handler sending records at or above level to ch.
+// A nil level shares the global level, so SetLevel applies to it as well.
+// The handler never closes ch.
+func NewChannelHandler(ch chan<- Record, lv *slog.LevelVar) *ChannelHandler {
+	if lv == nil {
+		ensureInit()
+		lv = level
+	}
+	return &ChannelHandler{level: lv, sink: &channelSink{ch: ch}}
+}
+
+// SetBlocking makes Handle wait for room in a full channel instead of
+// dropping the record, for consumers that must see every record (tests).
+// It applies to the handlers derived from h as well.
+func (h *ChannelHandler) SetBlocking(enabled bool) { h.sink.block.Store(enabled) }
+
+// Dropped returns how many records were dropped because the channel was full
+func (h *ChannelHandler) Dropped() uint64 { return h.sink.dropped.Load() }
+
+func (h *ChannelHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	if (silent && l < LevelFatal) || skipCancelled(ctx, l) {
+		return false
+	}
+	if forced, ok := forcedLevel(ctx); ok {
+		return l >= forced
+	}
+	if sub, ok := subsystemLevel(h.firstGroup(), h.handlerAttrs); ok {
+		return l >= sub
+	}
+	return l >= h.level.Level()
+}
+
+func (h *ChannelHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !admitRecord(ctx, &r, h.handlerAttrs) {
+		return nil
+	}
+	source, _ := takeSourceOverride(&r)
+
+	ctxAttrs := contextAttrs(ctx)
+	attrs := hookAttrs(r, h.groups, h.attrs, ctxAttrs)
+	if hooksDue(ctx) {
+		runHooks(r, attrs)
+	}
+	for i, a := range attrs {
+		a = redactAttr(a)
+		a.Value = a.Value.Resolve() // The consumer may read it later, on another goroutine
+		attrs[i] = a
+	}
+
+	rec := Record{Time: r.Time, Level: r.Level, Message: r.Message, Source: source, Attrs: attrs}
+	if h.sink.block.Load() {
+		select {
+		case h.sink.ch <- rec:
+		case <-ctx.Done():
+			h.sink.dropped.Add(1)
+		}
+		return nil
+	}
+	select {
+	case h.sink.ch <- rec:
+	default:
+		h.sink.dropped.Add(1)
+	}
+	return nil
+}
+
+// handlerAttrs returns the attrs added with WithAttrs, without group paths
+func (h *ChannelHandler) handlerAttrs() []slog.Attr {
+	attrs := make([]slog.Attr, len(h.attrs))
+	for i, ga := range h.attrs {
+		attrs[i] = ga.attr
+	}
+	return attrs
+}
+
+func (h *ChannelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ChannelHandler{
+		level:  h.level,
+		sink:   h.sink,
+		attrs:  appendGroupedAttrs(h.attrs, h.groups, attrs),
+		groups: h.groups,
+	}
+}
+
+func (h *ChannelHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	groups := make([]string, 0, len(h.groups)+1)
+	groups = append(groups, h.groups...)
+	return &ChannelHandler{
+		level:  h.level,
+		sink:   h.sink,
+		attrs:  h.attrs,
+		groups: append(groups, name),
+	}
+}
+
+// firstGroup returns the outermost group name ("" without groups)
+func (h *ChannelHandler) firstGroup() string {
+	if len(h.groups) == 0 {
+		return ""
+	}
+	return h.groups[0]
+}
+
+// withLevel returns a copy of the handler with its own minimum level
+func (h *ChannelHandler) withLevel(lv *slog.LevelVar) slog.Handler {
+	return &ChannelHandler{level: lv, sink: h.sink, attrs: h.attrs, groups: h.groups}
+}
+
+// Ensure ChannelHandler implements slog.Handler
+var _ slog.Handler = (*ChannelHandler)(nil)
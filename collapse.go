@@ -0,0 +1,124 @@
+package glogi
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	errorCollapse atomic.Int64 // Window in nanoseconds (0: collapsing disabled)
+	collapseMu    sync.Mutex
+	collapsed     = map[string]*collapseWindow{} // Record key -> open window
+)
+
+// collapseSummaryKey marks the context of a summary record so it isn't collapsed itself
+type collapseSummaryKey struct{}
+
+// collapseWindow tracks an error message whose first occurrence was logged
+type collapseWindow struct {
+	level slog.Level
+	msg   string
+	pc    uintptr   // Call site of the first occurrence
+	start time.Time // When the first occurrence was logged
+	count uint64    // Occurrences in the window, the first included
+	timer *time.Timer
+}
+
+// SetErrorCollapse logs the first ERROR record with a given message in full,
+// then drops identical ones for window and writes a single summary line when
+// the window closes:
+//
+//	"connect: connection refused" occurred 1423 times in last 5s occurrences=1423
+//
+// The summary has the level and source of the first record and is only written
+// if the message repeated. Records are identical when level and message match
+// (plus the attrs set with SetDedupKeyAttrs, e.g. "err" to tell causes apart).
+// FATAL and PANIC are never collapsed. Close writes pending summaries early.
+// window <= 0 disables collapsing and forgets open windows.
+//
+//	log.SetErrorCollapse(5 * time.Second)
+func SetErrorCollapse(window time.Duration) {
+	collapseMu.Lock()
+	defer collapseMu.Unlock()
+	errorCollapse.Store(int64(max(window, 0)))
+	for key, w := range collapsed {
+		w.timer.Stop()
+		delete(collapsed, key)
+	}
+}
+
+// collapseRecord reports whether r should be emitted under SetErrorCollapse:
+// the first record of a window is, the identical ones after it are counted
+func collapseRecord(ctx context.Context, r *slog.Record, handlerAttrs func() []slog.Attr) bool {
+	window := time.Duration(errorCollapse.Load())
+	if window <= 0 || r.Level < LevelError || r.Level >= LevelFatal || (ctx != nil && ctx.Value(collapseSummaryKey{}) != nil) {
+		return true
+	}
+	var attrs []slog.Attr
+	if len(dedupKeyAttrs) > 0 {
+		attrs = handlerAttrs()
+	}
+	key := recordKey(*r, attrs)
+
+	collapseMu.Lock()
+	defer collapseMu.Unlock()
+	if w := collapsed[key]; w != nil {
+		w.count++
+		return false
+	}
+	if len(collapsed) >= maxSampleKeys {
+		return true // Too many distinct errors to track: log them all
+	}
+	w := &collapseWindow{level: r.Level, msg: r.Message, pc: r.PC, start: clock(), count: 1}
+	w.timer = time.AfterFunc(window, func() { closeCollapseWindow(key, w, window) })
+	collapsed[key] = w
+	return true
+}
+
+// closeCollapseWindow ends the window of key and writes its summary
+func closeCollapseWindow(key string, w *collapseWindow, window time.Duration) {
+	collapseMu.Lock()
+	if collapsed[key] != w {
+		collapseMu.Unlock()
+		return // Reset by SetErrorCollapse or Close
+	}
+	delete(collapsed, key)
+	count := w.count
+	collapseMu.Unlock()
+
+	if count > 1 {
+		ensureInit()
+		writeCollapseSummary(w, count, window)
+	}
+}
+
+// flushCollapsed closes every open window early, writing the pending summaries
+func flushCollapsed() {
+	collapseMu.Lock()
+	windows := make([]*collapseWindow, 0, len(collapsed))
+	for key, w := range collapsed {
+		w.timer.Stop()
+		delete(collapsed, key)
+		windows = append(windows, w)
+	}
+	collapseMu.Unlock()
+
+	for _, w := range windows {
+		if w.count > 1 {
+			writeCollapseSummary(w, w.count, max(clock().Sub(w.start).Round(time.Millisecond), time.Millisecond))
+		}
+	}
+}
+
+// writeCollapseSummary writes the summary line of a window to the global logger
+func writeCollapseSummary(w *collapseWindow, count uint64, elapsed time.Duration) {
+	msg := fmt.Sprintf("%q occurred %d times in last %s", w.msg, count, elapsed)
+	r := slog.NewRecord(clock(), w.level, msg, w.pc)
+	r.AddAttrs(slog.Uint64("occurrences", count))
+	ctx := context.WithValue(context.Background(), collapseSummaryKey{}, true)
+	_ = logger().Handler().Handle(ctx, r)
+}
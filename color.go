@@ -0,0 +1,151 @@
+package glogi
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ColorDepth is the number of colors the terminal supports
+type ColorDepth int
+
+// Supported color depths
+const (
+	ColorDepth16        ColorDepth = 16
+	ColorDepth256       ColorDepth = 256
+	ColorDepthTrueColor ColorDepth = 1 << 24
+)
+
+// colorDepth is the depth colors are rendered at (detected in initConfig)
+var colorDepth = ColorDepthTrueColor
+
+// SetColorDepth sets the terminal color depth. 24-bit ("#ff8800") and 256-color
+// ("38;5;208") specs are downgraded to the nearest supported color when rendering,
+// so it can be changed at any time (e.g. after TERM changes under tmux/screen).
+func SetColorDepth(depth ColorDepth) { colorDepth = depth }
+
+// detectColorDepth guesses the terminal color depth from COLORTERM and TERM
+func detectColorDepth() ColorDepth {
+	switch strings.ToLower(os.Getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return ColorDepthTrueColor
+	}
+	if strings.Contains(os.Getenv("TERM"), "256color") {
+		return ColorDepth256
+	}
+	return ColorDepth16
+}
+
+// basic16 holds the RGB values of the 16 standard ANSI colors (xterm defaults)
+var basic16 = [16][3]int{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// hexColor converts "#rrggbb" to a 24-bit foreground ANSI sequence
+func hexColor(c string) (string, bool) {
+	if len(c) != 7 || c[0] != '#' {
+		return "", false
+	}
+	v, err := strconv.ParseUint(c[1:], 16, 32)
+	if err != nil {
+		return "", false
+	}
+	return fmt.Sprintf("\033[38;2;%d;%d;%dm", v>>16&0xff, v>>8&0xff, v&0xff), true
+}
+
+// adaptColor downgrades a 24-bit or 256-color ANSI sequence to the configured depth.
+// Other sequences are returned unchanged.
+func adaptColor(c string) string {
+	if colorDepth >= ColorDepthTrueColor || !strings.HasPrefix(c, "\033[") || !strings.HasSuffix(c, "m") {
+		return c
+	}
+	parts := strings.Split(c[2:len(c)-1], ";")
+	if len(parts) < 3 || (parts[0] != "38" && parts[0] != "48") {
+		return c
+	}
+	bg := parts[0] == "48"
+
+	var r, g, b int
+	switch {
+	case parts[1] == "2" && len(parts) == 5:
+		r, _ = strconv.Atoi(parts[2])
+		g, _ = strconv.Atoi(parts[3])
+		b, _ = strconv.Atoi(parts[4])
+		if colorDepth >= ColorDepth256 {
+			return fmt.Sprintf("\033[%s;5;%dm", parts[0], rgbTo256(r, g, b))
+		}
+	case parts[1] == "5" && len(parts) == 3:
+		n, _ := strconv.Atoi(parts[2])
+		if colorDepth >= ColorDepth256 {
+			return c
+		}
+		r, g, b = color256ToRGB(n)
+	default:
+		return c
+	}
+
+	idx := nearest16(r, g, b)
+	code := 30 + idx
+	if idx >= 8 {
+		code = 90 + idx - 8
+	}
+	if bg {
+		code += 10
+	}
+	return fmt.Sprintf("\033[%dm", code)
+}
+
+// rgbTo256 maps an RGB color to the nearest xterm 256-color index
+func rgbTo256(r, g, b int) int {
+	// Grayscale ramp (232-255) for neutral colors
+	if r == g && g == b {
+		switch {
+		case r < 8:
+			return 16
+		case r > 248:
+			return 231
+		default:
+			return 232 + (r-8)*24/241
+		}
+	}
+	// 6x6x6 color cube (16-231)
+	q := func(v int) int { return (v*5 + 127) / 255 }
+	return 16 + 36*q(r) + 6*q(g) + q(b)
+}
+
+// color256ToRGB returns the RGB value of an xterm 256-color index
+func color256ToRGB(n int) (int, int, int) {
+	switch {
+	case n < 16:
+		c := basic16[n]
+		return c[0], c[1], c[2]
+	case n < 232:
+		n -= 16
+		lvl := func(v int) int {
+			if v == 0 {
+				return 0
+			}
+			return 55 + v*40
+		}
+		return lvl(n / 36), lvl(n / 6 % 6), lvl(n % 6)
+	default:
+		v := 8 + (n-232)*10
+		return v, v, v
+	}
+}
+
+// nearest16 returns the index of the closest standard ANSI color
+func nearest16(r, g, b int) int {
+	best, bestDist := 0, -1
+	for i, c := range basic16 {
+		dr, dg, db := r-c[0], g-c[1], b-c[2]
+		if d := dr*dr + dg*dg + db*db; bestDist < 0 || d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
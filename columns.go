@@ -0,0 +1,47 @@
+package glogi
+
+// Declared attr columns of the text output (SetAttrColumns)
+var (
+	attrColumns     []string
+	attrColumnWidth int
+)
+
+// SetAttrColumns lays out the listed attr keys as aligned columns in the text
+// output: they come right after the message, in the given order, each
+// key=value segment padded to width characters (longer ones are not cut). A
+// record without one of the keys gets blank space in its place, so repeated
+// lines of a request log line up. Other attrs follow the columns. Keys are
+// matched with their group path (http.status). Call with no keys to disable.
+//
+//	log.SetAttrColumns(16, "method", "status", "user_id")
+func SetAttrColumns(width int, keys ...string) {
+	if width < 0 {
+		width = 0
+	}
+	attrColumns = append([]string(nil), keys...)
+	attrColumnWidth = width
+}
+
+// splitColumns takes the first field of each declared column out of fields.
+// cols has one entry per column, nil where the record has no such key.
+func splitColumns(fields [][]attrField) (cols []*attrField, rest [][]attrField) {
+	cols = make([]*attrField, len(attrColumns))
+	index := make(map[string]int, len(attrColumns))
+	for i, k := range attrColumns {
+		if _, dup := index[k]; !dup {
+			index[k] = i
+		}
+	}
+	rest = make([][]attrField, len(fields))
+	for li, fs := range fields {
+		for _, f := range fs {
+			if i, ok := index[f.key]; ok && cols[i] == nil {
+				f := f
+				cols[i] = &f
+				continue
+			}
+			rest[li] = append(rest[li], f)
+		}
+	}
+	return cols, rest
+}
@@ -1,75 +1,194 @@
 package glogi
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
-	"os"
+	"regexp"
 	"runtime"
-	"time"
+	"strings"
+	"sync"
 )
 
 // Backward compatibility with standard log package.
-// These functions log at INFO level (or ERROR for Fatal/Panic).
+// Print* log at INFO level (see SetCompatLevel), Fatal* at FATAL and Panic* at PANIC.
+
+// compatLevel is the level of Print, Println and Printf
+var compatLevel = LevelInfo
+
+// SetCompatLevel sets the level Print, Println and Printf log at (INFO by
+// default), e.g. LevelDebug to demote the chatter of code ported from the
+// standard log package in one call. Fatal* and Panic* are not affected.
+func SetCompatLevel(l slog.Level) { compatLevel = l }
 
 // logCompatWithCaller logs compat messages with correct caller
 func logCompatWithCaller(lvl slog.Level, msg string) {
 	ensureInit()
-	if !logger.Enabled(context.Background(), lvl) {
+	if !logger().Enabled(context.Background(), lvl) {
 		return
 	}
 
 	var pcs [1]uintptr
-	runtime.Callers(3, pcs[:]) // skip: Callers, logCompatWithCaller, Print*/Fatal*/Panic*
+	if captureSource(lvl) {
+		runtime.Callers(3+callerSkip, pcs[:]) // skip: Callers, logCompatWithCaller, Print*/Fatal*/Panic*
+	}
 
-	r := slog.NewRecord(time.Now(), lvl, msg, pcs[0])
-	_ = logger.Handler().Handle(context.Background(), r)
+	r := slog.NewRecord(clock(), lvl, msg, pcs[0])
+	_ = logger().Handler().Handle(context.Background(), r)
 }
 
-// Print logs arguments at INFO level (like fmt.Print)
+// Print logs arguments at the compat level (like fmt.Print)
 func Print(v ...any) {
-	logCompatWithCaller(LevelInfo, fmt.Sprint(v...))
+	logCompatWithCaller(compatLevel, fmt.Sprint(v...))
 }
 
-// Println logs arguments at INFO level (like fmt.Println)
+// Println logs arguments at the compat level (like fmt.Println)
 func Println(v ...any) {
-	logCompatWithCaller(LevelInfo, fmt.Sprint(v...))
+	logCompatWithCaller(compatLevel, fmt.Sprint(v...))
 }
 
-// Printf logs formatted message at INFO level
+// Printf logs formatted message at the compat level
 func Printf(format string, v ...any) {
-	logCompatWithCaller(LevelInfo, fmt.Sprintf(format, v...))
+	logCompatWithCaller(compatLevel, fmt.Sprintf(format, v...))
 }
 
 // Fatalln logs at FATAL level and exits
 func Fatalln(v ...any) {
 	logCompatWithCaller(LevelFatal, fmt.Sprint(v...))
-	os.Exit(1)
+	exit()
 }
 
 // Fatalf logs formatted message at FATAL level and exits
 func Fatalf(format string, v ...any) {
 	logCompatWithCaller(LevelFatal, fmt.Sprintf(format, v...))
-	os.Exit(1)
+	exit()
 }
 
 // Panic logs at PANIC level and panics (standard log.Panic signature)
 func Panic(v ...any) {
 	msg := fmt.Sprint(v...)
 	logCompatWithCaller(LevelPanic, msg)
-	panic(msg)
+	panicAfterLog(msg)
 }
 
 // Panicln logs at PANIC level and panics
 func Panicln(v ...any) {
 	msg := fmt.Sprint(v...)
 	logCompatWithCaller(LevelPanic, msg)
-	panic(msg)
+	panicAfterLog(msg)
 }
 
 // Panicf logs formatted message at PANIC level and panics
 func Panicf(format string, v ...any) {
 	msg := fmt.Sprintf(format, v...)
 	logCompatWithCaller(LevelPanic, msg)
-	panic(msg)
+	panicAfterLog(msg)
+}
+
+// StdLogWriter returns a writer that re-emits each line written to it as a
+// glogi record at level, with the line (without the newline) as the message.
+// Hand it to code that only accepts an io.Writer, like the standard logger:
+//
+//	stdlog.SetFlags(0) // glogi adds its own time and source
+//	stdlog.SetOutput(log.StdLogWriter(log.LevelInfo))
+//	http.Server{ErrorLog: stdlog.New(log.StdLogWriter(log.LevelError), "", 0)}
+//
+// Multi-line writes become one record per line; a trailing partial line is
+// buffered until its newline arrives. Empty lines are skipped, and a leading
+// standard logger date/time ("2009/11/10 23:00:00 ") is stripped. The source
+// is the first caller outside glogi and the standard log and fmt packages.
+func StdLogWriter(level slog.Level) io.Writer {
+	// Init now: it redirects the standard logger (slog.SetDefault), which
+	// would deadlock if it ran inside the standard logger's Write
+	ensureInit()
+	return &stdLogWriter{level: level}
+}
+
+// Level-specific StdLogWriters, one per legacy *log.Logger, so each subsystem
+// maps to its own level:
+//
+//	cacheLog := stdlog.New(log.StdDebugWriter(), "", stdlog.LstdFlags)
+//	billingLog := stdlog.New(log.StdErrorWriter(), "", stdlog.LstdFlags)
+
+// StdTraceWriter returns a StdLogWriter logging each line at TRACE
+func StdTraceWriter() io.Writer { return StdLogWriter(LevelTrace) }
+
+// StdDebugWriter returns a StdLogWriter logging each line at DEBUG
+func StdDebugWriter() io.Writer { return StdLogWriter(LevelDebug) }
+
+// StdInfoWriter returns a StdLogWriter logging each line at INFO
+func StdInfoWriter() io.Writer { return StdLogWriter(LevelInfo) }
+
+// StdWarnWriter returns a StdLogWriter logging each line at WARN
+func StdWarnWriter() io.Writer { return StdLogWriter(LevelWarn) }
+
+// StdErrorWriter returns a StdLogWriter logging each line at ERROR
+func StdErrorWriter() io.Writer { return StdLogWriter(LevelError) }
+
+// stdLogTimePrefix matches the date and time the standard logger writes with
+// Ldate, Ltime and Lmicroseconds (glogi adds its own)
+var stdLogTimePrefix = regexp.MustCompile(`^(?:\d{4}/\d{2}/\d{2} )?(?:\d{2}:\d{2}:\d{2}(?:\.\d{6})? )?`)
+
+// stdLogWriter implements StdLogWriter
+type stdLogWriter struct {
+	level slog.Level
+	mu    sync.Mutex
+	buf   []byte // Partial line awaiting its newline
+}
+
+func (w *stdLogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		line := strings.TrimSuffix(string(w.buf[:i]), "\r")
+		line = stdLogTimePrefix.ReplaceAllLiteralString(line, "")
+		w.buf = w.buf[i+1:]
+		if line != "" {
+			w.emit(line)
+		}
+	}
+	if len(w.buf) == 0 {
+		w.buf = nil // Don't pin a large payload's backing array
+	}
+	return len(p), nil
+}
+
+// emit logs one line, attributed to the first frame outside glogi, log and fmt
+func (w *stdLogWriter) emit(line string) {
+	ensureInit()
+	if !logger().Enabled(context.Background(), w.level) {
+		return
+	}
+
+	var pc [1]uintptr
+	if captureSource(w.level) {
+		// Count the logical frames to skip, then let Callers return a PC that
+		// resolves to the caller even when log.Printf & co. were inlined into it
+		var pcs [16]uintptr
+		n := runtime.Callers(3, pcs[:]) // skip: Callers, emit, Write
+		frames := runtime.CallersFrames(pcs[:n])
+		skip := 0
+		for {
+			f, more := frames.Next()
+			if !strings.HasPrefix(f.Function, glogiPkgPrefix) && !strings.HasPrefix(f.Function, "log.") && !strings.HasPrefix(f.Function, "fmt.") {
+				break
+			}
+			skip++
+			if !more {
+				break
+			}
+		}
+		runtime.Callers(3+skip, pc[:])
+	}
+
+	r := slog.NewRecord(clock(), w.level, line, pc[0])
+	_ = logger().Handler().Handle(context.Background(), r)
 }
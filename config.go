@@ -0,0 +1,67 @@
+package glogi
+
+import (
+	"io"
+	"os"
+)
+
+// Config configures the global logger programmatically, e.g. from an
+// application's own config file. Zero fields fall back to the env var (and
+// then the default) that Init would use, so env still works as a baseline.
+type Config struct {
+	Level         string    // Level name or number (LOG_LEVEL)
+	Output        io.Writer // Destination (LOG_FILE, stdout)
+	TimeFormat    string    // Timestamp layout (LOG_TIME_FORMAT)
+	DisableColors bool      // Turn colors off (LOG_NO_COLOR)
+	SourceWidth   int       // Source column width (LOG_SOURCE_WIDTH)
+	Format        string    // text, json or ecs (LOG_FORMAT)
+	RedactKeys    []string  // Attr keys to redact (LOG_REDACT_KEYS)
+}
+
+// InitWith initializes the global logger from cfg instead of env alone.
+// Unlike Init it always (re)builds the logger, so calling it again replaces
+// the previous configuration; an output opened from LOG_FILE is closed.
+//
+//	log.InitWith(log.Config{Level: cfg.Log.Level, Format: "json", Output: f})
+func InitWith(cfg Config) {
+	explicitInit.Store(true)
+	initMu.Lock()
+	defer initMu.Unlock()
+
+	initConfig() // Env values first, overridden by cfg below
+	if cfg.TimeFormat != "" {
+		SetTimeFormat(cfg.TimeFormat)
+	}
+	if cfg.DisableColors {
+		DisableColors()
+	}
+	if cfg.SourceWidth > 0 {
+		SetSourceWidth(cfg.SourceWidth)
+	}
+	if cfg.RedactKeys != nil {
+		SetRedactKeys(cfg.RedactKeys...)
+	}
+
+	levelName := os.Getenv("LOG_LEVEL")
+	if cfg.Level != "" {
+		levelName = cfg.Level
+		pendingLevel = nil // cfg wins over SetLevel before InitWith
+	}
+	format := os.Getenv("LOG_FORMAT")
+	if cfg.Format != "" {
+		format = cfg.Format
+	}
+
+	if ownedOutput != nil {
+		_ = ownedOutput.Close()
+		ownedOutput = nil
+	}
+	out := cfg.Output
+	if out == nil {
+		out = pendingOutput
+	}
+	if out == nil {
+		out = initOutput()
+	}
+	setupLogger(out, levelName, format)
+}
@@ -0,0 +1,150 @@
+package glogi
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Context keys used by glogi
+type ctxKey int
+
+const (
+	workerLabelKey ctxKey = iota
+	forceLevelKey
+)
+
+// Worker labels
+//
+// Go has no goroutine-local storage, so a worker label travels with the
+// request instead: either in a context.Context (WithWorkerLabel) or in a
+// logger bound to the label (LoggerForWorker). Both tag records with a
+// "worker" attribute. SetWorkerLabel sets the label of records that carry
+// neither, e.g. for a process that is itself one worker of a pool.
+//
+//	for i := 0; i < n; i++ {
+//	    go func(id int) {
+//	        ctx := log.WithWorkerLabel(ctx, fmt.Sprintf("w%d", id))
+//	        slog.InfoContext(ctx, "job done") // ... worker=w3
+//	    }(i)
+//	}
+
+// workerLabel is the label of records without a context label (SetWorkerLabel)
+var workerLabel string
+
+// SetWorkerLabel tags every record with worker=label unless its context
+// carries a label of its own (WithWorkerLabel), e.g. the pod's index in a
+// worker deployment. It is process-wide, not per goroutine: Go has no
+// goroutine-local storage, so per-goroutine labels go in a context or a
+// LoggerForWorker logger, which take precedence. "" removes the label.
+func SetWorkerLabel(label string) { workerLabel = label }
+
+// WithWorkerLabel returns a copy of ctx carrying the worker label.
+// Records logged with this context (e.g. slog.InfoContext) get a worker=label attr.
+func WithWorkerLabel(ctx context.Context, label string) context.Context {
+	return context.WithValue(ctx, workerLabelKey, label)
+}
+
+// WorkerLabel returns the worker label stored in ctx, if any
+func WorkerLabel(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	label, ok := ctx.Value(workerLabelKey).(string)
+	return label, ok
+}
+
+// LoggerForWorker returns a logger on the global logger's destination and
+// level that tags every record with worker=label. A label in the record's
+// context (WithWorkerLabel) takes precedence.
+func LoggerForWorker(label string) *Logger {
+	ensureInit()
+	l := defaultLogger()
+	return &Logger{sl: l.sl, level: l.level, worker: label}
+}
+
+// ForceLevel returns a copy of ctx that overrides the minimum level for records
+// logged with it, regardless of the global setting. Use it to turn on verbose
+// logging for a single request, e.g. when its trace is sampled:
+//
+//	if span.IsSampled() {
+//	    ctx = log.ForceLevel(ctx, log.LevelDebug)
+//	}
+//	slog.DebugContext(ctx, "cache lookup", "key", k) // emitted even at global INFO
+func ForceLevel(ctx context.Context, level slog.Level) context.Context {
+	return context.WithValue(ctx, forceLevelKey, level)
+}
+
+// forcedLevel returns the level override stored in ctx, if any
+func forcedLevel(ctx context.Context) (slog.Level, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	l, ok := ctx.Value(forceLevelKey).(slog.Level)
+	return l, ok
+}
+
+// skipCancelledContext drops DEBUG/TRACE records whose context is done
+var skipCancelledContext bool
+
+// SetSkipCancelledContext drops records below INFO whose context is already
+// cancelled or past its deadline, saving the formatting work for requests
+// nobody waits for anymore. WARN and above are always kept. Off by default.
+func SetSkipCancelledContext(enabled bool) { skipCancelledContext = enabled }
+
+// skipCancelled reports whether a record at l is dropped because ctx is done
+func skipCancelled(ctx context.Context, l slog.Level) bool {
+	return skipCancelledContext && l < LevelInfo && ctx != nil && ctx.Err() != nil
+}
+
+// logDeadline enables the deadline_in attr for records logged with a context
+var logDeadline bool
+
+// SetLogDeadline enables adding the remaining context deadline as a deadline_in
+// attr to records logged with a context (e.g. slog.InfoContext). Records whose
+// context has no deadline are unaffected; an expired deadline shows as negative.
+func SetLogDeadline(enabled bool) { logDeadline = enabled }
+
+// contextExtractors are the functions registered with RegisterContextExtractor
+var contextExtractors []func(ctx context.Context) []slog.Attr
+
+// RegisterContextExtractor registers a function that derives attrs from the
+// context of each record (e.g. a trace or request ID stored by middleware).
+// Extractors run on every record logged with a context (InfoContext,
+// slog.InfoContext, ...) in registration order, after the built-in worker and
+// deadline_in attrs. Register extractors at startup, before logging.
+//
+//	log.RegisterContextExtractor(func(ctx context.Context) []slog.Attr {
+//	    if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+//	        return []slog.Attr{slog.String("request_id", id)}
+//	    }
+//	    return nil
+//	})
+func RegisterContextExtractor(fn func(ctx context.Context) []slog.Attr) {
+	contextExtractors = append(contextExtractors, fn)
+}
+
+// contextAttrs returns the attrs glogi derives from a record's context,
+// followed by the global fields
+func contextAttrs(ctx context.Context) []slog.Attr {
+	var attrs []slog.Attr
+	if label, ok := WorkerLabel(ctx); ok {
+		attrs = append(attrs, slog.String("worker", label))
+	} else if workerLabel != "" {
+		attrs = append(attrs, slog.String("worker", workerLabel))
+	}
+	if ctx != nil {
+		if logDeadline {
+			if deadline, ok := ctx.Deadline(); ok {
+				attrs = append(attrs, slog.Duration("deadline_in", time.Until(deadline)))
+			}
+		}
+		for _, fn := range contextExtractors {
+			attrs = append(attrs, fn(ctx)...)
+		}
+	}
+	if attrs == nil {
+		return globalFields
+	}
+	return append(attrs, globalFields...)
+}
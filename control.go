@@ -0,0 +1,91 @@
+package glogi
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	stripControlChars bool // Sanitize user text in the text format
+	stripControlSet   bool // SetStripControlChars was called; otherwise on when uncolored
+)
+
+// SetStripControlChars removes ANSI escape sequences and escapes other control
+// characters (\n, \r, \x07, ...; tabs are kept) in messages, keys, sources and
+// multi-line values of the text format, so text echoed from elsewhere can't
+// inject fake lines or drive the terminal. glogi's own colors are unaffected.
+// By default it is on for output written without colors. Quoted attr values,
+// logfmt and JSON are always escaped.
+func SetStripControlChars(enabled bool) {
+	stripControlChars, stripControlSet = enabled, true
+}
+
+// stripControlOn reports whether user text is sanitized for output with or without colors
+func stripControlOn(colors bool) bool {
+	if stripControlSet {
+		return stripControlChars
+	}
+	return !colors
+}
+
+// isControl reports whether r is a C0 or C1 control character other than tab
+func isControl(r rune) bool {
+	return (r < 0x20 && r != '\t') || (r >= 0x7f && r <= 0x9f)
+}
+
+// stripControl removes ANSI escape sequences from s and escapes the other
+// control characters
+func stripControl(s string) string {
+	if strings.IndexFunc(s, isControl) < 0 {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c == 0x1b { // ESC: skip the whole sequence
+			i = skipEscape(s, i+1)
+			continue
+		}
+		r, size := rune(c), 1
+		if c >= 0x80 {
+			r, size = utf8.DecodeRuneInString(s[i:])
+		}
+		if isControl(r) {
+			b.WriteString(strings.Trim(strconv.QuoteRune(r), "'"))
+		} else {
+			b.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return b.String()
+}
+
+// skipEscape returns the index after the escape sequence whose body starts at
+// s[i] (CSI "[...final", OSC "]...BEL" or "]...ESC\", or a single character)
+func skipEscape(s string, i int) int {
+	if i >= len(s) {
+		return i
+	}
+	switch s[i] {
+	case '[':
+		for i++; i < len(s); i++ {
+			if s[i] >= 0x40 && s[i] <= 0x7e {
+				return i + 1
+			}
+		}
+		return i
+	case ']':
+		for i++; i < len(s); i++ {
+			if s[i] == 0x07 {
+				return i + 1
+			}
+			if s[i] == 0x1b && i+1 < len(s) && s[i+1] == '\\' {
+				return i + 2
+			}
+		}
+		return i
+	default:
+		return i + 1
+	}
+}
@@ -0,0 +1,53 @@
+package glogi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// DumpKey is the attr key of the value logged by DebugJSON
+const DumpKey = "value"
+
+// jsonDump is a value marshaled by DebugJSON. The text format prints it
+// indented below the line; the structured handlers embed it as JSON.
+type jsonDump struct {
+	indented []byte
+}
+
+// MarshalJSON implements json.Marshaler, embedding the value compactly
+func (d jsonDump) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	if err := json.Compact(&b, d.indented); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// String returns the indented JSON
+func (d jsonDump) String() string { return string(d.indented) }
+
+// DebugJSON logs v as indented JSON at DEBUG level, e.g. to see everything in
+// a struct while developing. The text format prints the JSON below the line,
+// one line per row; JSON output embeds it as value. v is only marshaled when
+// DEBUG is enabled, so the call is cheap in production. Values that can't be
+// marshaled are logged as %+v.
+//
+//	log.DebugJSON("loaded config", cfg)
+//	// ... DEBUG [main.go:20] loaded config
+//	//   | value:
+//	//   |   {
+//	//   |     "port": 8080
+//	//   |   }
+func DebugJSON(msg string, v any) {
+	if !Enabled(LevelDebug) {
+		return
+	}
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		logWithCaller(context.Background(), LevelDebug, msg, DumpKey, fmt.Sprintf("%+v", v))
+		return
+	}
+	logWithCaller(context.Background(), LevelDebug, msg, DumpKey, jsonDump{indented: b})
+}
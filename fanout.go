@@ -0,0 +1,150 @@
+package glogi
+
+import (
+	"context"
+	"log/slog"
+)
+
+// extraHandlers are the handlers added with AddHandler, kept across Init
+var extraHandlers []slog.Handler
+
+// AddHandler makes the global logger send every record to h as well as to
+// its own handler, e.g. colored console output plus JSON lines for a log
+// shipper from a single call:
+//
+//	f, _ := os.OpenFile("app.json", os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+//	log.AddHandler(log.NewJSONHandler(f, levelVar))
+//
+// Each handler applies its own level. Volume controls (rate limits, error
+// collapsing, sampling) decide once per record, and glogi handlers then all
+// agree on it; hooks run once per record too. Output settings (SetOutput,
+// SetAsync, AddOutput, ...) apply to the global logger's own handler only.
+// Call it after Init; handlers are kept when the logger is set up again.
+func AddHandler(h slog.Handler) {
+	if h == nil {
+		return
+	}
+	ensureInit()
+	initMu.Lock()
+	defer initMu.Unlock()
+	extraHandlers = append(extraHandlers, h)
+	global.Store(&Logger{sl: slog.New(withExtraHandlers(primaryHandler())), level: level})
+	if bindSlogDefault {
+		slog.SetDefault(logger())
+	}
+}
+
+// withExtraHandlers wraps h to fan records out to the AddHandler handlers
+func withExtraHandlers(h slog.Handler) slog.Handler {
+	if len(extraHandlers) == 0 {
+		return h
+	}
+	return &fanoutHandler{primary: h, extra: append([]slog.Handler(nil), extraHandlers...)}
+}
+
+// primaryHandler returns the global logger's own handler, without the AddHandler ones
+func primaryHandler() slog.Handler {
+	return unwrapHandler(logger().Handler())
+}
+
+// unwrapHandler returns the first handler of a fanout, or h itself
+func unwrapHandler(h slog.Handler) slog.Handler {
+	if f, ok := h.(*fanoutHandler); ok {
+		return f.primary
+	}
+	return h
+}
+
+// fanoutKey marks the context of a record sent to several handlers
+type fanoutKey struct{}
+
+// fanoutDecision is admitRecord's verdict on a fanned-out record, made by the
+// first glogi handler that handles it and reused by the others
+type fanoutDecision struct {
+	done   bool
+	admit  bool
+	hooked bool        // The hooks ran for the record
+	r      slog.Record // The record as admitted (sequence number, suppressed counts, ...)
+}
+
+// fanoutHandler sends each record to its primary handler and the extra ones
+type fanoutHandler struct {
+	primary slog.Handler
+	extra   []slog.Handler
+}
+
+func (h *fanoutHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	if h.primary.Enabled(ctx, l) {
+		return true
+	}
+	for _, e := range h.extra {
+		if e.Enabled(ctx, l) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *fanoutHandler) Handle(ctx context.Context, r slog.Record) error {
+	ctx = context.WithValue(ctx, fanoutKey{}, &fanoutDecision{})
+	var err error
+	if h.primary.Enabled(ctx, r.Level) {
+		err = h.primary.Handle(ctx, r.Clone())
+	}
+	for _, e := range h.extra {
+		if e.Enabled(ctx, r.Level) {
+			if eerr := e.Handle(ctx, r.Clone()); err == nil {
+				err = eerr
+			}
+		}
+	}
+	return err
+}
+
+func (h *fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	extra := make([]slog.Handler, len(h.extra))
+	for i, e := range h.extra {
+		extra[i] = e.WithAttrs(attrs)
+	}
+	return &fanoutHandler{primary: h.primary.WithAttrs(attrs), extra: extra}
+}
+
+func (h *fanoutHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	extra := make([]slog.Handler, len(h.extra))
+	for i, e := range h.extra {
+		extra[i] = e.WithGroup(name)
+	}
+	return &fanoutHandler{primary: h.primary.WithGroup(name), extra: extra}
+}
+
+// withLevel returns a copy whose primary handler has its own minimum level;
+// the extra handlers keep theirs
+func (h *fanoutHandler) withLevel(lv *slog.LevelVar) slog.Handler {
+	lh, ok := h.primary.(levelHandler)
+	if !ok {
+		return h
+	}
+	return &fanoutHandler{primary: lh.withLevel(lv), extra: h.extra}
+}
+
+// withIndent returns a copy whose primary handler indents one scope deeper
+func (h *fanoutHandler) withIndent() slog.Handler {
+	ih, ok := h.primary.(indentHandler)
+	if !ok {
+		return h
+	}
+	return &fanoutHandler{primary: ih.withIndent(), extra: h.extra}
+}
+
+// fanoutAdmit returns the decision shared by the handlers of a fanned-out record
+// (nil when the record isn't fanned out)
+func fanoutAdmit(ctx context.Context) *fanoutDecision {
+	if ctx == nil {
+		return nil
+	}
+	d, _ := ctx.Value(fanoutKey{}).(*fanoutDecision)
+	return d
+}
@@ -0,0 +1,65 @@
+package glogi
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// resetFanout removes the AddHandler handlers and the hooks after a test
+func resetFanout(t *testing.T) {
+	t.Cleanup(func() {
+		extraHandlers = nil
+		hooks = nil
+	})
+}
+
+func TestAddHandlerWritesBothOutputs(t *testing.T) {
+	buf := captureOutput(t, "text")
+	resetFanout(t)
+	var jsonBuf bytes.Buffer
+	AddHandler(NewJSONHandler(&jsonBuf, &slog.LevelVar{}))
+
+	Info("fanned out", "k", 1)
+	if !strings.Contains(buf.String(), "fanned out k=1") {
+		t.Errorf("primary output = %q", buf.String())
+	}
+	if !strings.Contains(jsonBuf.String(), `"msg":"fanned out"`) {
+		t.Errorf("added handler output = %q", jsonBuf.String())
+	}
+}
+
+func TestAddHandlerRunsHooksOnce(t *testing.T) {
+	captureOutput(t, "text")
+	resetFanout(t)
+	calls := 0
+	AddHook(func(slog.Level, string, []slog.Attr) { calls++ })
+	AddHandler(NewJSONHandler(io.Discard, &slog.LevelVar{}))
+
+	Info("x")
+	if calls != 1 {
+		t.Errorf("hook calls = %d, want 1", calls)
+	}
+}
+
+func TestAddHandlerWhileLogging(t *testing.T) {
+	captureOutput(t, "text")
+	resetFanout(t)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				Info("tick")
+			}
+		}()
+	}
+	for i := 0; i < 3; i++ {
+		AddHandler(NewJSONHandler(io.Discard, &slog.LevelVar{}))
+	}
+	wg.Wait()
+}
@@ -0,0 +1,30 @@
+package glogi
+
+import (
+	"log/slog"
+	"runtime"
+)
+
+// recordFilter drops records for which it returns false (nil: keep all)
+var recordFilter func(r slog.Record) bool
+
+// SetFilter sets a predicate called for every record before it is written, in
+// all handlers; returning false drops the record regardless of its level.
+// Dropped records don't count towards rate limits, sampling or Stats. nil
+// removes the filter. Set it at startup, before logging.
+//
+//	log.SetFilter(func(r slog.Record) bool {
+//	    file, _ := log.RecordSource(r)
+//	    return !strings.Contains(file, "/github.com/noisy/sdk/")
+//	})
+func SetFilter(fn func(r slog.Record) bool) { recordFilter = fn }
+
+// RecordSource returns the absolute file path and line that logged r, or an
+// empty file if the record has no source (e.g. with SetIncludeSource(false))
+func RecordSource(r slog.Record) (string, int) {
+	if r.PC == 0 {
+		return "", 0
+	}
+	f, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+	return f.File, f.Line
+}
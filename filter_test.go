@@ -0,0 +1,52 @@
+package glogi
+
+import (
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFilterByMessage(t *testing.T) {
+	for _, format := range []string{"text", "json", "logfmt"} {
+		t.Run(format, func(t *testing.T) {
+			buf := captureOutput(t, format)
+			SetFilter(func(r slog.Record) bool { return !strings.Contains(r.Message, "healthcheck") })
+			t.Cleanup(func() { SetFilter(nil) })
+
+			Info("GET /healthcheck")
+			Error("healthcheck failed") // Dropped whatever the level
+			Info("GET /users")
+			if out := buf.String(); strings.Contains(out, "healthcheck") || !strings.Contains(out, "GET /users") {
+				t.Errorf("output = %q", out)
+			}
+		})
+	}
+}
+
+func TestFilterBySource(t *testing.T) {
+	buf := captureOutput(t, "text")
+	var file string
+	SetFilter(func(r slog.Record) bool {
+		file, _ = RecordSource(r)
+		return filepath.Base(file) != "filter_test.go"
+	})
+	t.Cleanup(func() { SetFilter(nil) })
+
+	Info("from the test file")
+	if buf.Len() != 0 {
+		t.Errorf("output = %q", buf.String())
+	}
+	if filepath.Base(file) != "filter_test.go" {
+		t.Errorf("filter saw source %q", file)
+	}
+}
+
+func TestFilterNilKeepsAll(t *testing.T) {
+	buf := captureOutput(t, "text")
+	SetFilter(nil)
+	Info("kept")
+	if !strings.Contains(buf.String(), "kept") {
+		t.Errorf("output = %q", buf.String())
+	}
+}
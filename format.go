@@ -0,0 +1,52 @@
+package glogi
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"runtime"
+)
+
+// previewKey marks the context of a record rendered by Format
+type previewKey struct{}
+
+// previewing reports whether ctx belongs to a record rendered by Format
+func previewing(ctx context.Context) bool {
+	return ctx != nil && ctx.Value(previewKey{}) != nil
+}
+
+// Format returns the line the global logger would write for a record, without
+// writing it, e.g. to assert on exact output in tests or to preview the
+// configuration in a UI. It uses the current format and settings (colors as
+// for the main output, time format, widths, redaction, ...) and the caller's
+// source location; the result ends with the line terminator. The level
+// threshold is ignored, and the record doesn't count toward rate limits,
+// sampling, Stats or sequence numbers, nor run hooks. Records dropped by
+// SetFilter format as "". With a non-glogi handler it returns "".
+//
+//	line := log.Format(log.LevelInfo, "user created", "id", 42)
+func Format(level slog.Level, msg string, args ...any) string {
+	ensureInit()
+	h, ok := primaryHandler().(writerHandler)
+	if !ok {
+		return ""
+	}
+	var buf bytes.Buffer
+	ph, ok := h.withWriter(&buf).(writerHandler)
+	if !ok {
+		return ""
+	}
+	if ref, ok := ph.output().(*outputRef); ok {
+		ref.tees.Store(nil) // Extra outputs don't get the preview
+	}
+
+	var pcs [1]uintptr
+	runtime.Callers(2, pcs[:]) // skip: Callers, Format
+	r := slog.NewRecord(clock(), level, msg, pcs[0])
+	r.Add(args...)
+	ctx := context.WithValue(context.Background(), previewKey{}, true)
+	if err := ph.Handle(ctx, r); err != nil {
+		return ""
+	}
+	return buf.String()
+}
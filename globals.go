@@ -0,0 +1,42 @@
+package glogi
+
+import (
+	"log/slog"
+	"time"
+)
+
+// globalFields are the attrs set with SetGlobalFields, added to every record
+var globalFields []slog.Attr
+
+// SetGlobalFields attaches fields to every record of every glogi logger (the
+// global one, its children and New loggers), e.g. the host and pid in
+// aggregated logs. Args are key-value pairs or slog.Attr, like Info. A func()
+// any value is called for each record, for fields that change; other values
+// are fixed. Each call replaces the previous fields; no args removes them.
+// They are written where context attrs are (after the record's attrs in text).
+//
+//	host, _ := os.Hostname()
+//	log.SetGlobalFields("host", host, "pid", os.Getpid(), "version", func() any { return build.Version() })
+func SetGlobalFields(args ...any) {
+	if len(args) == 0 {
+		globalFields = nil
+		return
+	}
+	r := slog.NewRecord(time.Time{}, 0, "", 0)
+	r.Add(args...)
+	fields := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		if fn, ok := a.Value.Any().(func() any); ok && a.Value.Kind() == slog.KindAny {
+			a.Value = slog.AnyValue(dynamicValue(fn))
+		}
+		fields = append(fields, a)
+		return true
+	})
+	globalFields = fields
+}
+
+// dynamicValue is a global field value computed for each record
+type dynamicValue func() any
+
+// LogValue implements slog.LogValuer
+func (fn dynamicValue) LogValue() slog.Value { return slog.AnyValue(fn()) }
@@ -14,21 +14,33 @@ package glogi
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/neoff/glogi/rotate"
 )
 
 var (
-	logger   *slog.Logger
-	level    *slog.LevelVar
-	initOnce sync.Once
-	isInit   bool
+	global      atomic.Pointer[Logger] // Instance the package-level functions delegate to; replaced by Init and AddHandler
+	level       *slog.LevelVar
+	initMu      sync.Mutex  // Serializes Init and Close
+	initDone    atomic.Bool // Set once the global logger is ready; cleared by Close
+	ownedOutput io.Closer   // Output opened by Init (LOG_FILE), closed by Close
 )
 
+// defaultLogger returns the instance the package-level functions delegate to
+func defaultLogger() *Logger { return global.Load() }
+
+// logger returns the global logger's slog.Logger
+func logger() *slog.Logger { return global.Load().sl }
+
 // Custom log levels
 const (
 	LevelTrace = slog.Level(-8)
@@ -42,113 +54,518 @@ const (
 
 // Init initializes the global logger.
 // Reads LOG_LEVEL from environment variable (default: INFO).
-// Valid values: TRACE, DEBUG, INFO, WARN, ERROR
+// Valid values: TRACE, DEBUG, INFO, WARN, ERROR, FATAL, PANIC or a number (e.g. -8)
+// Reads LOG_FORMAT to select the output format: text (default, colored), json, ecs, gcp or logfmt.
+// LOG_FILE writes to a size-rotated file instead of stdout.
+// LOG_SPLIT_STREAMS=1 sends WARN and above to stderr (see SetSplitOutput).
+// Calling Init again has no effect until Close.
+// SetLevel, SetLevelValue and SetOutput called before Init take precedence
+// over the environment.
 func Init() {
-	initOnce.Do(func() {
-		level = &slog.LevelVar{}
-		level.Set(parseLevel(os.Getenv("LOG_LEVEL")))
+	explicitInit.Store(true)
+	initLogger()
+}
+
+// initLogger initializes the global logger from env unless it is ready
+func initLogger() {
+	if initDone.Load() {
+		return
+	}
+	initMu.Lock()
+	defer initMu.Unlock()
+	if initDone.Load() {
+		return
+	}
+
+	out := pendingOutput
+	if out == nil {
+		out = initOutput()
+	}
+	setupLogger(out, os.Getenv("LOG_LEVEL"), os.Getenv("LOG_FORMAT"))
+}
+
+var (
+	// Settings made before the global logger exists, applied when it is set up
+	pendingLevel  *slog.Level
+	pendingOutput io.Writer
+
+	explicitInit       atomic.Bool // Init or InitWith was called
+	strictInit         bool        // Warn when logging triggers the default init
+	implicitInitWarned atomic.Bool
+)
+
+// SetStrictInit makes glogi print a one-time warning on stderr when a log
+// call (or a setter that needs the logger) initializes it implicitly, before
+// Init or InitWith ran, to catch configuration that runs too late.
+func SetStrictInit(enabled bool) { strictInit = enabled }
 
-		handler := NewColoredHandler(os.Stdout, level)
-		logger = slog.New(handler)
-		slog.SetDefault(logger)
-		isInit = true
-	})
+// setPending runs fn under initMu if the global logger isn't set up yet and
+// reports whether it did. fn records a setting for setupLogger to apply.
+func setPending(fn func()) bool {
+	initMu.Lock()
+	defer initMu.Unlock()
+	if initDone.Load() {
+		return false
+	}
+	fn()
+	return true
+}
+
+// setupLogger builds the global logger writing to w and marks it ready.
+// A level set before Init overrides levelName. Caller must hold initMu.
+func setupLogger(w io.Writer, levelName, format string) {
+	level = &slog.LevelVar{}
+	level.Set(parseLevel(levelName))
+	if pendingLevel != nil {
+		level.Set(*pendingLevel)
+	}
+	pendingLevel, pendingOutput = nil, nil
+
+	startTime = clock()
+	global.Store(&Logger{sl: slog.New(withExtraHandlers(handlerForFormat(format, w, level))), level: level})
+	if bindSlogDefault {
+		slog.SetDefault(logger())
+	}
+
+	if v := strings.ToLower(os.Getenv("LOG_SPLIT_STREAMS")); v == "1" || v == "true" {
+		splitOutput = true
+		applySplitOutput()
+	}
+	initDone.Store(true)
+	if configBanner {
+		logConfigBanner(w)
+	}
 }
 
-// SetLevel changes the minimum log level at runtime
+// initOutput returns the global logger's destination: a rotating file if
+// LOG_FILE is set (sized by LOG_FILE_MAX_BYTES and LOG_FILE_MAX_BACKUPS),
+// otherwise stdout. Falls back to stdout with a warning if the file can't be opened.
+func initOutput() io.Writer {
+	path := os.Getenv("LOG_FILE")
+	if path == "" {
+		return os.Stdout
+	}
+	maxBytes := int64(100 << 20)
+	if v, err := strconv.ParseInt(os.Getenv("LOG_FILE_MAX_BYTES"), 10, 64); err == nil {
+		maxBytes = v
+	}
+	maxBackups := 5
+	if v, err := strconv.Atoi(os.Getenv("LOG_FILE_MAX_BACKUPS")); err == nil {
+		maxBackups = v
+	}
+	w, err := rotate.NewRotatingWriter(path, maxBytes, maxBackups)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "glogi: cannot open LOG_FILE: %v, using stdout\n", err)
+		return os.Stdout
+	}
+	ownedOutput = w
+	return w
+}
+
+// SLogger returns the global logger as a *slog.Logger, for libraries that take
+// one. Records logged through it use glogi's formatting, level and output,
+// and report the library's call site as source.
+//
+//	client := thirdparty.New(thirdparty.WithLogger(log.SLogger()))
+func SLogger() *slog.Logger {
+	ensureInit()
+	return logger()
+}
+
+// Default returns the global logger as a *slog.Logger (same as SLogger)
+func Default() *slog.Logger {
+	return SLogger()
+}
+
+// bindSlogDefault keeps glogi installed as the slog default logger
+var bindSlogDefault = true
+
+// BindSlogDefault controls whether glogi installs itself as the slog default
+// logger (on by default). While on, SetLevel, SetLevelValue and SetOutput
+// re-install it if other code replaced it with slog.SetDefault, so slog.Info
+// and glogi.Info keep going to the same place during a migration. Turning it
+// on installs glogi right away; turning it off leaves the slog default alone.
+func BindSlogDefault(enabled bool) {
+	bindSlogDefault = enabled
+	if enabled {
+		ensureInit()
+		rebindSlogDefault()
+	}
+}
+
+// rebindSlogDefault re-installs the global logger as the slog default if
+// binding is on and something else replaced it
+func rebindSlogDefault() {
+	if bindSlogDefault && initDone.Load() && slog.Default() != logger() {
+		slog.SetDefault(logger())
+	}
+}
+
+// Handler returns the global logger's handler, e.g. to wrap it in another
+// slog.Handler or build a *slog.Logger with extra attrs
+func Handler() slog.Handler {
+	return SLogger().Handler()
+}
+
+// newFormatHandler creates the handler selected by LOG_FORMAT
+func newFormatHandler(w io.Writer, lv *slog.LevelVar) slog.Handler {
+	return handlerForFormat(os.Getenv("LOG_FORMAT"), w, lv)
+}
+
+// handlerForFormat creates the handler for a format name: text (default), json, ecs or logfmt
+func handlerForFormat(format string, w io.Writer, lv *slog.LevelVar) slog.Handler {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "json":
+		return NewJSONHandler(w, lv)
+	case "ecs":
+		return NewECSHandler(w, lv)
+	case "gcp":
+		return NewGCPHandler(w, lv)
+	case "logfmt":
+		return NewLogfmtHandler(w, lv)
+	default:
+		return NewColoredHandler(w, lv)
+	}
+}
+
+// SetLevel changes the minimum log level at runtime. Called before Init, it
+// is kept and applied when the logger is set up, taking precedence over LOG_LEVEL.
 func SetLevel(l string) {
-	if level != nil {
-		level.Set(parseLevel(l))
+	SetLevelValue(parseLevel(l))
+}
+
+// SetLevelValue sets the minimum log level to an exact value, including the
+// custom levels (LevelTrace, LevelFatal, LevelPanic) and values between them.
+// Like SetLevel, it can be called before Init.
+func SetLevelValue(l slog.Level) {
+	if setPending(func() { pendingLevel = &l }) {
+		return
 	}
+	level.Set(l)
+	rebindSlogDefault()
+}
+
+// GetLevel returns the current minimum log level, e.g. to restore it later:
+//
+//	prev := log.GetLevel()
+//	log.SetLevelValue(log.LevelFatal)
+//	defer log.SetLevelValue(prev)
+func GetLevel() slog.Level {
+	ensureInit()
+	return level.Level()
 }
 
+// silent mutes everything below FATAL, whatever the level
+var silent bool
+
+// SetSilent mutes all records below FATAL while on, e.g. for a CLI's --quiet
+// flag. FATAL and PANIC still get through so crashes stay visible. Levels are
+// left untouched: SetSilent(false) brings back exactly what was enabled before,
+// even if the level was changed in between.
+func SetSilent(on bool) { silent = on }
+
+// parseLevel converts a level name (TRACE, DEBUG, INFO, WARN, ERROR, FATAL, PANIC
+// or one added with RegisterLevel) or a raw integer (e.g. "-8", "2") to a
+// level. Empty input means INFO;
+// unrecognized values fall back to INFO with a warning on stderr.
 func parseLevel(s string) slog.Level {
-	switch strings.ToUpper(strings.TrimSpace(s)) {
+	l, ok := lookupLevel(s)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "glogi: unknown log level %q, using INFO\n", s)
+		return LevelInfo
+	}
+	return l
+}
+
+// lookupLevel is parseLevel without the fallback; ok is false for unrecognized values
+func lookupLevel(s string) (slog.Level, bool) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	switch s {
 	case "TRACE":
-		return LevelTrace
+		return LevelTrace, true
 	case "DEBUG":
-		return LevelDebug
+		return LevelDebug, true
+	case "", "INFO":
+		return LevelInfo, true
 	case "WARN", "WARNING":
-		return LevelWarn
+		return LevelWarn, true
 	case "ERROR":
-		return LevelError
-	default:
-		return LevelInfo
+		return LevelError, true
+	case "FATAL":
+		return LevelFatal, true
+	case "PANIC":
+		return LevelPanic, true
+	}
+	if cl, ok := customLevelNames[s]; ok {
+		return cl.level, true
 	}
+	if n, err := strconv.Atoi(s); err == nil {
+		return slog.Level(n), true
+	}
+	return LevelInfo, false
 }
 
+// ensureInit lazily initializes the global logger (again, after Close).
+// Init is mutex-guarded so concurrent first calls are race-free.
 func ensureInit() {
-	if !isInit {
-		Init()
+	if initDone.Load() {
+		return
 	}
+	if strictInit && !explicitInit.Load() && implicitInitWarned.CompareAndSwap(false, true) {
+		fmt.Fprintln(os.Stderr, "glogi: logger used before Init, initializing from env; settings applied later may be too late")
+	}
+	initLogger()
 }
 
-// logWithCaller logs with the correct caller information
-// calldepth indicates how many stack frames to skip
-func logWithCaller(lvl slog.Level, msg string, args ...any) {
+// Enabled reports whether the global logger emits records at lvl. Use it to
+// skip building expensive args that would be thrown away:
+//
+//	if log.Enabled(log.LevelDebug) {
+//	    log.Debug("state", "dump", expensiveDump())
+//	}
+func Enabled(lvl slog.Level) bool {
 	ensureInit()
-	if !logger.Enabled(context.Background(), lvl) {
+	return logger().Enabled(context.Background(), lvl)
+}
+
+// TraceEnabled reports whether TRACE records are emitted
+func TraceEnabled() bool { return Enabled(LevelTrace) }
+
+// DebugEnabled reports whether DEBUG records are emitted
+func DebugEnabled() bool { return Enabled(LevelDebug) }
+
+// TraceFn logs at TRACE level with the message and args returned by fn, which
+// is only called when TRACE is enabled. Unlike Trace, a disabled call doesn't
+// box args into an []any, so it doesn't allocate in hot loops:
+//
+//	log.TraceFn(func() (string, []any) { return "tick", []any{"i", i} })
+func TraceFn(fn func() (string, []any)) {
+	if !Enabled(LevelTrace) {
 		return
 	}
+	msg, args := fn()
+	logWithCaller(context.Background(), LevelTrace, msg, args...)
+}
 
-	// Get caller info (skip: runtime.Caller, logWithCaller, public func, actual caller)
-	var pcs [1]uintptr
-	runtime.Callers(3, pcs[:])
+// DebugFn is TraceFn at DEBUG level
+func DebugFn(fn func() (string, []any)) {
+	if !Enabled(LevelDebug) {
+		return
+	}
+	msg, args := fn()
+	logWithCaller(context.Background(), LevelDebug, msg, args...)
+}
 
-	r := slog.NewRecord(time.Now(), lvl, msg, pcs[0])
+// logWithCaller logs with the correct caller information.
+// ctx is passed to the handler (level overrides, context attrs).
+func logWithCaller(ctx context.Context, lvl slog.Level, msg string, args ...any) {
+	ensureInit()
+	// One extra frame (logWithCaller) between the public func and Logger.log
+	defaultLogger().log(ctx, 1, lvl, msg, args...)
+}
+
+// clock returns the time stamped on records (SetClock)
+var clock = time.Now
+
+// SetClock replaces time.Now as the source of record timestamps, and of the
+// current time for rate limits, sampling and error collapsing, e.g. to get
+// stable timestamps in golden-file tests. Durations (Timed, ScopedLogger,
+// request latency) still use the real clock. Set it before Init so relative
+// timestamps start from it; nil restores time.Now.
+//
+//	log.SetClock(func() time.Time { return time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC) })
+func SetClock(fn func() time.Time) {
+	if fn == nil {
+		fn = time.Now
+	}
+	clock = fn
+}
+
+// logAtPC logs a record attributed to an already captured caller PC.
+// The caller is responsible for the level check.
+func logAtPC(lvl slog.Level, pc uintptr, msg string, args ...any) {
+	args, ok := checkArgs(msg, pc, args)
+	if !ok {
+		return
+	}
+	r := slog.NewRecord(clock(), lvl, msg, pc)
 	r.Add(args...)
-	_ = logger.Handler().Handle(context.Background(), r)
+	_ = logger().Handler().Handle(context.Background(), r)
 }
 
 // Trace logs at TRACE level (light gray)
 func Trace(msg string, args ...any) {
-	logWithCaller(LevelTrace, msg, args...)
+	logWithCaller(context.Background(), LevelTrace, msg, args...)
 }
 
 // Debug logs at DEBUG level (gray)
 func Debug(msg string, args ...any) {
-	logWithCaller(LevelDebug, msg, args...)
+	logWithCaller(context.Background(), LevelDebug, msg, args...)
 }
 
 // Info logs at INFO level (no color)
 func Info(msg string, args ...any) {
-	logWithCaller(LevelInfo, msg, args...)
+	logWithCaller(context.Background(), LevelInfo, msg, args...)
 }
 
 // Warn logs at WARN level (yellow)
 func Warn(msg string, args ...any) {
-	logWithCaller(LevelWarn, msg, args...)
+	logWithCaller(context.Background(), LevelWarn, msg, args...)
 }
 
 // Error logs at ERROR level (red)
 func Error(msg string, args ...any) {
-	logWithCaller(LevelError, msg, args...)
+	logWithCaller(context.Background(), LevelError, msg, args...)
 }
 
-// Fatal logs at FATAL level (red) and calls os.Exit(1)
+// Fatal logs at FATAL level (red) and exits (see RegisterExitHandler)
 func Fatal(msg string, args ...any) {
-	logWithCaller(LevelFatal, msg, args...)
-	os.Exit(1)
+	logWithCaller(context.Background(), LevelFatal, msg, args...)
+	exit()
 }
 
 // PanicLog logs at PANIC level (red) and panics
 func PanicLog(msg string, args ...any) {
-	logWithCaller(LevelPanic, msg, args...)
-	panic(msg)
+	logWithCaller(context.Background(), LevelPanic, msg, args...)
+	panicAfterLog(msg)
+}
+
+// Context variants pass ctx to the handler, so context values (worker label,
+// ForceLevel, deadline) apply and custom handlers can read request-scoped values.
+
+// TraceContext logs at TRACE level with a context
+func TraceContext(ctx context.Context, msg string, args ...any) {
+	logWithCaller(ctx, LevelTrace, msg, args...)
+}
+
+// DebugContext logs at DEBUG level with a context
+func DebugContext(ctx context.Context, msg string, args ...any) {
+	logWithCaller(ctx, LevelDebug, msg, args...)
+}
+
+// InfoContext logs at INFO level with a context
+func InfoContext(ctx context.Context, msg string, args ...any) {
+	logWithCaller(ctx, LevelInfo, msg, args...)
+}
+
+// WarnContext logs at WARN level with a context
+func WarnContext(ctx context.Context, msg string, args ...any) {
+	logWithCaller(ctx, LevelWarn, msg, args...)
+}
+
+// ErrorContext logs at ERROR level with a context
+func ErrorContext(ctx context.Context, msg string, args ...any) {
+	logWithCaller(ctx, LevelError, msg, args...)
 }
 
 // Recover catches panic and logs it with stack trace. Use in defer.
+// The record's source is the line that panicked, not the deferred call.
 func Recover() {
 	if r := recover(); r != nil {
-		ensureInit()
-		buf := make([]byte, 4096)
+		logPanic(r)
+	}
+}
+
+// RecoverAndExit logs a panic like Recover, then exits with status 1 (after
+// the exit handlers and Flush), so a supervisor can restart the process.
+// Use in defer at the top of main or a worker goroutine.
+func RecoverAndExit() {
+	if r := recover(); r != nil {
+		logPanic(r)
+		exit()
+	}
+}
+
+// RecoverRepanic logs a panic like Recover, flushes the output and panics
+// again with the original value, so outer recovers and frameworks still see
+// it. Use in defer.
+func RecoverRepanic() {
+	if r := recover(); r != nil {
+		logPanic(r)
+		_ = Flush()
+		panic(r)
+	}
+}
+
+// maxStackBufferSize bounds the stack buffer grown with SetFullStack
+const maxStackBufferSize = 64 << 20
+
+var (
+	stackBufferSize = 4096 // Bytes of stack the Recover functions capture
+	fullStack       bool   // Grow the buffer until the whole stack fits
+)
+
+// SetStackBufferSize sets how many bytes of the goroutine's stack Recover,
+// RecoverAndExit and RecoverRepanic capture (4096 by default); a deeper
+// stack is cut at that size unless SetFullStack is on. n <= 0 restores 4096.
+func SetStackBufferSize(n int) {
+	if n <= 0 {
+		n = 4096
+	}
+	stackBufferSize = n
+}
+
+// SetFullStack makes the Recover functions capture the whole stack of the
+// panicking goroutine, doubling the buffer from SetStackBufferSize until it
+// fits (up to 64 MiB), so deep panics keep the frames that caused them.
+// Off by default: the stack is cut at the buffer size.
+func SetFullStack(enabled bool) { fullStack = enabled }
+
+// panicStack returns the calling goroutine's stack, cut at the buffer size
+// unless SetFullStack is on
+func panicStack() []byte {
+	buf := make([]byte, stackBufferSize)
+	for {
 		n := runtime.Stack(buf, false)
+		if n < len(buf) || !fullStack || len(buf) >= maxStackBufferSize {
+			return buf[:n]
+		}
+		buf = make([]byte, min(2*len(buf), maxStackBufferSize))
+	}
+}
+
+// logPanic logs a recovered panic value with the stack at the panic site.
+// Must be called directly from a Recover* function while panicking.
+func logPanic(r any) {
+	ensureInit()
+	stack := panicStack()
 
-		var pcs [1]uintptr
-		runtime.Callers(2, pcs[:])
+	rec := slog.NewRecord(clock(), LevelPanic, fmt.Sprintf("recovered: %v", r), panicPC())
+	rec.Add("stack", string(stack))
+	_ = logger().Handler().Handle(context.Background(), rec)
+}
+
+// panicPC returns the PC of the frame that raised the panic being recovered.
+// Must be called directly from logPanic while panicking. Runtime frames
+// (gopanic, sigpanic, panicIndex, ...) and glogi frames (PanicLog) are skipped;
+// falls back to the Recover* caller when no panic frame is found.
+func panicPC() uintptr {
+	if !includeSource {
+		return 0
+	}
+	var pcs [64]uintptr
+	n := runtime.Callers(4, pcs[:]) // skip: Callers, panicPC, logPanic, Recover*
+	if n == 0 {
+		return 0
+	}
 
-		rec := slog.NewRecord(time.Now(), LevelPanic, fmt.Sprintf("recovered: %v", r), pcs[0])
-		rec.Add("stack", string(buf[:n]))
-		_ = logger.Handler().Handle(context.Background(), rec)
+	seenPanic, afterSigpanic := false, false
+	for _, pc := range pcs[:n] {
+		f, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		switch {
+		case f.Function == "runtime.gopanic":
+			seenPanic = true
+		case !seenPanic:
+		case strings.HasPrefix(f.Function, "runtime."):
+			afterSigpanic = f.Function == "runtime.sigpanic"
+		case strings.HasPrefix(f.Function, glogiPkgPrefix):
+		default:
+			if afterSigpanic {
+				// A faulting PC, not a return address: undo the -1 applied when resolving
+				return pc + 1
+			}
+			return pc
+		}
 	}
+	return pcs[0]
 }
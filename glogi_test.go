@@ -0,0 +1,73 @@
+package glogi
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// captureOutput sets the global logger up again with the given LOG_FORMAT at
+// TRACE level, writing to the returned buffer, and closes it after the test
+func captureOutput(t testing.TB, format string) *bytes.Buffer {
+	t.Helper()
+	_ = Close()
+	t.Setenv("LOG_FORMAT", format)
+	t.Setenv("LOG_LEVEL", "TRACE")
+	t.Setenv("LOG_FILE", "")
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	Init()
+	t.Cleanup(func() { _ = Close() })
+	return &buf
+}
+
+func BenchmarkDisabledLevel(b *testing.B) {
+	captureOutput(b, "text")
+	SetLevel("INFO")
+	b.Run("TraceFn", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			TraceFn(func() (string, []any) { return "tick", []any{"i", i} })
+		}
+	})
+	b.Run("DebugFn", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			DebugFn(func() (string, []any) { return "tick", []any{"i", i} })
+		}
+	})
+	b.Run("Trace", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			Trace("tick", "i", i) // Boxes i into the args slice before the level check
+		}
+	})
+}
+
+func TestDisabledLazyLevelsDontAllocate(t *testing.T) {
+	buf := captureOutput(t, "text")
+	SetLevel("INFO")
+	i := 0
+	built := false
+	if n := testing.AllocsPerRun(100, func() {
+		i++
+		TraceFn(func() (string, []any) { built = true; return "tick", []any{"i", i} })
+	}); n != 0 {
+		t.Errorf("disabled TraceFn: %v allocs per call, want 0", n)
+	}
+	if n := testing.AllocsPerRun(100, func() {
+		i++
+		DebugFn(func() (string, []any) { built = true; return "tick", []any{"i", i} })
+	}); n != 0 {
+		t.Errorf("disabled DebugFn: %v allocs per call, want 0", n)
+	}
+	if built || buf.Len() != 0 {
+		t.Errorf("disabled levels built or wrote records: %q", buf.String())
+	}
+
+	SetLevel("TRACE")
+	TraceFn(func() (string, []any) { return "tick", []any{"i", 1} })
+	if !strings.Contains(buf.String(), "tick i=1") {
+		t.Errorf("enabled TraceFn output = %q", buf.String())
+	}
+}
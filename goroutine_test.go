@@ -0,0 +1,54 @@
+package glogi
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestGoroutineIDDiffersPerGoroutine(t *testing.T) {
+	buf := captureOutput(t, "json")
+	SetIncludeGoroutineID(true)
+	t.Cleanup(func() { SetIncludeGoroutineID(false) })
+
+	ids := make([]uint64, 2)
+	var wg sync.WaitGroup
+	for i := range ids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ids[i] = goroutineID()
+			Info("hello", "n", i)
+		}(i)
+	}
+	wg.Wait()
+
+	logged := map[int]uint64{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var rec struct {
+			N    int    `json:"n"`
+			Goid uint64 `json:"goid"`
+		}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("invalid JSON %q: %v", line, err)
+		}
+		logged[rec.N] = rec.Goid
+	}
+	if logged[0] == 0 || logged[1] == 0 || logged[0] == logged[1] {
+		t.Errorf("goids = %v, want two different non-zero ids", logged)
+	}
+	for i, id := range ids {
+		if logged[i] != id {
+			t.Errorf("goroutine %d logged goid %d, want %d", i, logged[i], id)
+		}
+	}
+}
+
+func TestGoroutineIDOffByDefault(t *testing.T) {
+	buf := captureOutput(t, "json")
+	Info("hello")
+	if strings.Contains(buf.String(), `"goid"`) {
+		t.Errorf("output = %q", buf.String())
+	}
+}
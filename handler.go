@@ -2,20 +2,27 @@ package glogi
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"math"
 	"os"
+	"reflect"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 // Default ANSI color codes
 const (
 	defaultColorReset     = "\033[0m"
 	defaultColorDarkGray  = "\033[90m" // TRACE & DEBUG - gray
-	defaultColorCyan      = "\033[36m" // unused
+	defaultColorCyan      = "\033[36m" // Attr values
 	defaultColorLightGray = "\033[37m" // unused
 	defaultColorYellow    = "\033[33m" // WARN
 	defaultColorRed       = "\033[31m" // ERROR, FATAL, PANIC
@@ -32,10 +39,19 @@ var (
 	colorWarn      = defaultColorYellow
 	colorError     = defaultColorRed
 	colorSource    = defaultColorGreen
-	colorsDisabled = false
+	colorKey       = defaultColorDarkGray // Attr keys, dimmed
+	colorValue     = defaultColorCyan
+	colorsDisabled = false // Colors off everywhere (LOG_NO_COLOR=1, DisableColors)
+	colorsForced   = false // Colors on even when not writing to a terminal (LOG_NO_COLOR=0, EnableColors)
 	configLoaded   = false
+	lineTerminator = "\n" // Record separator written after every record
+	timeFormat     = defaultTimeFormat
+	timeUTC        = false
 )
 
+// defaultTimeFormat is the timestamp layout of the text output
+const defaultTimeFormat = "2006/01/02 15:04:05"
+
 // initConfig reads configuration from environment variables
 func initConfig() {
 	if configLoaded {
@@ -50,9 +66,102 @@ func initConfig() {
 		}
 	}
 
-	// Disable colors
-	if os.Getenv("LOG_NO_COLOR") == "1" || os.Getenv("LOG_NO_COLOR") == "true" {
+	// Compact text output
+	switch strings.ToLower(os.Getenv("LOG_COMPACT")) {
+	case "1", "true":
+		compact = true
+	}
+
+	// Source location on/off
+	switch strings.ToLower(os.Getenv("LOG_SOURCE")) {
+	case "off", "0", "false":
+		includeSource = false
+	}
+
+	// Slow duration coloring
+	if t := os.Getenv("LOG_SLOW_THRESHOLD"); t != "" {
+		if d, err := time.ParseDuration(t); err == nil {
+			slowThreshold = d
+		}
+	}
+
+	// Source path mode
+	if m := os.Getenv("LOG_SOURCE_MODE"); m != "" {
+		SetSourceMode(SourceMode(strings.ToLower(strings.TrimSpace(m))))
+	}
+
+	// Caller function name in the source
+	if v := os.Getenv("LOG_SOURCE_FUNC"); v == "1" || v == "true" {
+		showFunction = true
+	}
+
+	// Level label: name, number or both
+	if m := os.Getenv("LOG_LEVEL_DISPLAY"); m != "" {
+		SetLevelDisplay(LevelDisplay(strings.ToLower(strings.TrimSpace(m))))
+	}
+
+	// Color depth from terminal capabilities
+	colorDepth = detectColorDepth()
+
+	// Timestamp layout and zone
+	if f, ok := os.LookupEnv("LOG_TIME_FORMAT"); ok {
+		SetTimeFormat(parseTimeFormat(f))
+	}
+	if u := os.Getenv("LOG_TIME_UTC"); u == "1" || u == "true" {
+		timeUTC = true
+	}
+	if v := os.Getenv("LOG_RELATIVE_TIME"); v == "1" || v == "true" {
+		relativeTime = true
+	}
+
+	// Configuration line on Init
+	if v := os.Getenv("LOG_CONFIG_BANNER"); v == "1" || v == "true" {
+		configBanner = true
+	}
+
+	// Per-subsystem levels
+	if v := os.Getenv("LOG_LEVELS"); v != "" {
+		parseSubsystemLevels(v)
+	}
+
+	// Attrs sorted by key
+	if v := os.Getenv("LOG_SORT_ATTRS"); v == "1" || v == "true" {
+		sortAttrs = true
+	}
+	if v := os.Getenv("LOG_ATTRS_POSITION"); v != "" {
+		SetAttrsPosition(AttrsPosition(strings.ToLower(strings.TrimSpace(v))))
+	}
+
+	// Redacted attribute keys
+	if keys := os.Getenv("LOG_REDACT_KEYS"); keys != "" {
+		SetRedactKeys(strings.Split(keys, ",")...)
+	}
+
+	// Secret scrubbing of messages and attr values
+	if v := os.Getenv("LOG_SECRET_SCRUB"); v == "1" || v == "true" {
+		SetSecretScrubbing(true)
+	}
+
+	// JSON group style
+	if os.Getenv("LOG_GROUP_STYLE") != "" {
+		SetGroupStyle(GroupStyle(strings.ToLower(strings.TrimSpace(os.Getenv("LOG_GROUP_STYLE")))))
+	}
+
+	// Disable colors (or force them on for non-terminal output). The standard
+	// NO_COLOR (any non-empty value) disables them; LOG_NO_COLOR takes precedence.
+	if os.Getenv("NO_COLOR") != "" {
+		colorsDisabled = true
+	}
+	switch os.Getenv("LOG_NO_COLOR") {
+	case "1", "true":
 		colorsDisabled = true
+	case "0", "false":
+		colorsDisabled, colorsForced = false, true
+	}
+
+	// Lowest colored level
+	if l := os.Getenv("LOG_COLOR_MIN_LEVEL"); l != "" {
+		colorMinLevel = parseLevel(l)
 	}
 
 	// Custom colors (ANSI codes like "32" for green, or named colors)
@@ -74,10 +183,17 @@ func initConfig() {
 	if c := os.Getenv("LOG_COLOR_SOURCE"); c != "" {
 		colorSource = parseColor(c)
 	}
+	if c, ok := os.LookupEnv("LOG_COLOR_KEY"); ok {
+		colorKey = parseColor(c)
+	}
+	if c, ok := os.LookupEnv("LOG_COLOR_VALUE"); ok {
+		colorValue = parseColor(c)
+	}
 }
 
 // parseColor converts color config to ANSI code
-// Accepts: "32" (just code) or "\033[32m" (full ANSI) or "green" (named)
+// Accepts: "32" (just code), "38;5;208" (256-color), "#ff8800" (24-bit),
+// "\033[32m" (full ANSI) or "green" (named)
 func parseColor(c string) string {
 	c = strings.TrimSpace(c)
 	if c == "" {
@@ -104,12 +220,16 @@ func parseColor(c string) string {
 	case "none", "off":
 		return ""
 	}
+	// 24-bit hex color
+	if ansi, ok := hexColor(c); ok {
+		return ansi
+	}
 	// If already contains escape sequence
 	if strings.Contains(c, "\033") || strings.Contains(c, "\\033") {
 		return strings.ReplaceAll(c, "\\033", "\033")
 	}
-	// Just a number - wrap in ANSI
-	if _, err := strconv.Atoi(c); err == nil {
+	// Just a number (or SGR parameters like "38;5;208") - wrap in ANSI
+	if strings.Trim(c, "0123456789;") == "" {
 		return fmt.Sprintf("\033[%sm", c)
 	}
 	return c
@@ -122,6 +242,91 @@ func SetSourceWidth(width int) {
 	}
 }
 
+// sourceFormatter renders the source location; nil means "file.go:42"
+var sourceFormatter func(file string, line int, fn string) string
+
+// SetSourceFormatter sets a custom function rendering the source location.
+// It receives the absolute file path, the line and the fully qualified function
+// name; glogi still pads/truncates and colors the result. nil restores the
+// default ("file.go:42").
+//
+//	log.SetSourceFormatter(func(file string, line int, fn string) string {
+//	    return fmt.Sprintf("%s:%d %s", filepath.Base(file), line, fn[strings.LastIndex(fn, ".")+1:])
+//	})
+func SetSourceFormatter(fn func(file string, line int, fn string) string) { sourceFormatter = fn }
+
+// SetTimeFormat sets the timestamp layout of the text output (time.Format
+// layout, default "2006/01/02 15:04:05"). An empty layout omits the timestamp,
+// e.g. when the container runtime already timestamps each line.
+func SetTimeFormat(layout string) { timeFormat = layout }
+
+// SetTimeUTC makes all handlers render timestamps in UTC instead of local time
+func SetTimeUTC(utc bool) { timeUTC = utc }
+
+var (
+	relativeTime bool         // Text timestamps as time since Init (LOG_RELATIVE_TIME)
+	startTime    = time.Now() // Monotonic start for relative timestamps, reset by Init
+)
+
+// SetRelativeTime replaces the text output's timestamp with the time elapsed
+// since Init, e.g. [+1234ms], to see the gaps between lines while profiling
+// startup. It is measured on the monotonic clock. Off by default.
+func SetRelativeTime(enabled bool) { relativeTime = enabled }
+
+// relativeStamp renders the time elapsed between Init and t
+func relativeStamp(t time.Time) string {
+	return fmt.Sprintf("+%dms", t.Sub(startTime).Milliseconds())
+}
+
+// attrTimeFormat is the layout of time.Time attr values ("": the line's layout)
+var attrTimeFormat string
+
+// SetAttrTimeFormat sets the layout of time.Time attr values in the text
+// output. By default they use the record timestamp's layout (SetTimeFormat),
+// or RFC 3339 when the timestamp is omitted, and are shown in the same zone
+// (local time, or UTC with SetTimeUTC).
+func SetAttrTimeFormat(layout string) { attrTimeFormat = layout }
+
+// formatAttrTime renders a time.Time attr value like the record timestamp
+func formatAttrTime(t time.Time) string {
+	layout := attrTimeFormat
+	if layout == "" {
+		layout = timeFormat
+	}
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	if !timeUTC {
+		t = t.Local() // Same zone as the record timestamp
+	}
+	return recordTime(t).Format(layout)
+}
+
+// parseTimeFormat maps LOG_TIME_FORMAT values to a layout.
+// Accepts "none"/"off" (no timestamp), a few well-known names or a raw layout.
+func parseTimeFormat(f string) string {
+	switch strings.ToLower(strings.TrimSpace(f)) {
+	case "", "none", "off":
+		return ""
+	case "rfc3339":
+		return time.RFC3339
+	case "rfc3339ms":
+		return "2006-01-02T15:04:05.000Z07:00"
+	case "rfc3339nano":
+		return time.RFC3339Nano
+	default:
+		return f
+	}
+}
+
+// recordTime returns t in the configured zone
+func recordTime(t time.Time) time.Time {
+	if timeUTC {
+		return t.UTC()
+	}
+	return t
+}
+
 // SetColorTrace sets the color for TRACE level
 func SetColorTrace(color string) { colorTrace = parseColor(color) }
 
@@ -137,21 +342,158 @@ func SetColorWarn(color string) { colorWarn = parseColor(color) }
 // SetColorError sets the color for ERROR level
 func SetColorError(color string) { colorError = parseColor(color) }
 
+// colorFunc picks the color of each level, replacing the per-level colors (nil: off)
+var colorFunc func(l slog.Level) string
+
+// SetColorFunc sets a function returning the color of a level, used instead
+// of the SetColorXxx and RegisterLevel colors for the level label and the
+// message, e.g. to color custom levels or grade colors by severity. It returns
+// the same color names or codes as SetColorError ("" leaves the level plain).
+// Colors stay off when disabled. nil restores the per-level colors.
+//
+//	log.SetColorFunc(func(l slog.Level) string {
+//	    if l >= log.LevelError {
+//	        return "#ff0000"
+//	    }
+//	    return "" // Plain below ERROR
+//	})
+func SetColorFunc(fn func(l slog.Level) string) { colorFunc = fn }
+
 // SetColorSource sets the color for source location
 func SetColorSource(color string) { colorSource = parseColor(color) }
 
+// SetColorKey sets the color for attr keys ("" leaves them uncolored)
+func SetColorKey(color string) { colorKey = parseColor(color) }
+
+// SetColorValue sets the color for attr values ("" leaves them uncolored)
+func SetColorValue(color string) { colorValue = parseColor(color) }
+
+// SetLineTerminator sets the record separator written after every record
+// (default "\n"). All handlers honor it, e.g. "\x00" for NUL-framed streams.
+// Records never contain the separator unless a message or attr value does.
+func SetLineTerminator(sep string) { lineTerminator = sep }
+
+// replaceAttr rewrites attrs before the text handler renders them
+var replaceAttr func(groups []string, a slog.Attr) slog.Attr
+
+// SetReplaceAttr sets a hook called for every record, handler and context attr
+// of the text handler, like slog.HandlerOptions.ReplaceAttr: groups is the
+// group path of the attr, and returning an attr with an empty key drops it.
+// It is not called for group attrs themselves, only for their members.
+//
+//	log.SetReplaceAttr(func(groups []string, a slog.Attr) slog.Attr {
+//	    if a.Key == "elapsed" {
+//	        return slog.String("took", a.Value.Duration().Round(time.Millisecond).String())
+//	    }
+//	    return a
+//	})
+func SetReplaceAttr(fn func(groups []string, a slog.Attr) slog.Attr) { replaceAttr = fn }
+
+// Text output attr framing: " key=value" by default
+var (
+	attrSeparator = " "
+	kvDelimiter   = "="
+)
+
+// SetAttrSeparator sets the string written before each key=value pair of the
+// text output (default " "), e.g. "\t" for tab-separated fields. An empty
+// separator is rejected and the current one kept. Values containing the
+// separator are quoted.
+func SetAttrSeparator(sep string) error {
+	if sep == "" || sep == kvDelimiter {
+		return fmt.Errorf("glogi: invalid attr separator %q", sep)
+	}
+	attrSeparator = sep
+	return nil
+}
+
+// SetKeyValueDelimiter sets the string between an attr key and its value in
+// the text output (default "="), e.g. ":" for key:value. An empty delimiter,
+// or one equal to the attr separator, is rejected and the current one kept.
+// Values containing the delimiter are quoted.
+func SetKeyValueDelimiter(delim string) error {
+	if delim == "" || delim == attrSeparator {
+		return fmt.Errorf("glogi: invalid key-value delimiter %q", delim)
+	}
+	kvDelimiter = delim
+	return nil
+}
+
+// Fixed text written around every text and logfmt record
+var (
+	linePrefix string
+	lineSuffix string
+)
+
+// SetPrefix sets a string written at the very start of every text and logfmt
+// record, before the timestamp, e.g. "[tenant=acme] " for a log shipper that
+// keys on a leading tag. It is written as is: uncolored, and without an added
+// space. "" (the default) removes it.
+func SetPrefix(prefix string) { linePrefix = prefix }
+
+// SetSuffix sets a string written at the end of every text and logfmt record,
+// before the line terminator. "" (the default) removes it.
+func SetSuffix(suffix string) { lineSuffix = suffix }
+
+// compact drops the time, source and colors from text output (LOG_COMPACT)
+var compact bool
+
+// SetCompact switches the text handler to the shortest format,
+// "LEVEL message key=value", without time, source or colors. Meant for CI
+// and test output; LOG_COMPACT=1 enables it from env.
+func SetCompact(enabled bool) { compact = enabled }
+
+// colorMinLevel is the lowest level whose lines are colored
+var colorMinLevel = slog.Level(math.MinInt)
+
+// SetColorLevels colors only records at or above min when colors are on;
+// lower levels are written as plain text. For example LevelWarn draws the eye
+// to warnings and errors in `less -R` while everything else stays plain.
+// LOG_COLOR_MIN_LEVEL sets it from env.
+func SetColorLevels(min slog.Level) { colorMinLevel = min }
+
+// handlerAttrsFirst writes With attrs before the call-site attrs
+var handlerAttrsFirst = true
+
+// SetAttrOrder sets where attrs added with With/WithAttrs go relative to the
+// attrs passed at the call site: before them when handlerFirst is true (the
+// default, like slog's own handlers), after them otherwise. Context attrs
+// keep their place. The order is stable either way, so golden-file tests
+// can rely on it.
+//
+//	l := log.With("svc", "api")
+//	l.Info("hit", "path", "/") // ... hit svc=api path=/
+//	log.SetAttrOrder(false)
+//	l.Info("hit", "path", "/") // ... hit path=/ svc=api
+func SetAttrOrder(handlerFirst bool) { handlerAttrsFirst = handlerFirst }
+
 // DisableColors disables all color output
-func DisableColors() { colorsDisabled = true }
+func DisableColors() { colorsDisabled, colorsForced = true, false }
 
-// EnableColors enables color output
-func EnableColors() { colorsDisabled = false }
+// EnableColors enables color output, even when not writing to a terminal
+func EnableColors() { colorsDisabled, colorsForced = false, true }
+
+// ColorsEnabled reports whether the global logger currently writes colors:
+// it uses the text or logfmt format, colors aren't disabled (DisableColors,
+// NO_COLOR, LOG_NO_COLOR, compact output), and they are forced on or the
+// output is a terminal. Code building its own colored messages can use it to
+// match glogi. SetColorLevels may still leave lower levels uncolored.
+func ColorsEnabled() bool {
+	ensureInit()
+	h, ok := primaryHandler().(*ColoredHandler)
+	return ok && h.colorsOn()
+}
 
 // ColoredHandler implements slog.Handler with colored level output
 type ColoredHandler struct {
 	level  *slog.LevelVar
-	writer io.Writer
-	attrs  []slog.Attr
+	writer *outputRef
+	attrs  []groupedAttr
 	groups []string
+
+	replaceAttr func(groups []string, a slog.Attr) slog.Attr // Overrides the global hook
+	logfmt      bool                                         // Strict logfmt output (NewLogfmtHandler)
+	indent      int                                          // Message indentation level (Logger.Group)
 }
 
 // NewColoredHandler creates a new colored handler
@@ -159,126 +501,1065 @@ func NewColoredHandler(w io.Writer, level *slog.LevelVar) *ColoredHandler {
 	initConfig() // Read config from env on first handler creation
 	return &ColoredHandler{
 		level:  level,
-		writer: w,
+		writer: newOutputRef(w),
 	}
 }
 
-func (h *ColoredHandler) Enabled(_ context.Context, l slog.Level) bool {
+// colorsOn reports whether this handler writes colors: by default only to a
+// terminal, unless colors are forced on or disabled globally (or compact output is on)
+func (h *ColoredHandler) colorsOn() bool {
+	return !colorsDisabled && !compact && (colorsForced || h.writer.IsTerminal())
+}
+
+func (h *ColoredHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	if (silent && l < LevelFatal) || skipCancelled(ctx, l) {
+		return false
+	}
+	if forced, ok := forcedLevel(ctx); ok {
+		return l >= forced
+	}
+	if sub, ok := subsystemLevel(h.firstGroup(), h.handlerAttrs); ok {
+		return l >= sub
+	}
 	return l >= h.level.Level()
 }
 
-func (h *ColoredHandler) Handle(_ context.Context, r slog.Record) error {
-	// Format: [2025/12/26 15:04:05] LEVEL [source_location] message key=value...
-	timeStr := r.Time.Format("2006/01/02 15:04:05")
-	levelStr, levelColor := h.formatLevelWithColor(r.Level)
+func (h *ColoredHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !admitRecord(ctx, &r, h.handlerAttrs) {
+		return nil
+	}
+
+	// Get source location from PC; a record without one keeps the column
+	loc, overridden := takeSourceOverride(&r)
+	if loc == "" && !overridden && !h.logfmt && includeSource && r.Level >= sourceMinLevel {
+		loc = unknownSource
+	}
+	if compact {
+		loc = ""
+	}
+
+	// Add attributes, prefixed with the active group path (http.method=GET)
+	replace := h.replaceAttr
+	if replace == nil {
+		replace = replaceAttr
+	}
+	var recFields, handlerFields, ctxFields []attrField
+	r.Attrs(func(a slog.Attr) bool {
+		recFields = appendAttrFields(recFields, replace, h.groups, a, 0)
+		return true
+	})
+
+	// Add handler-level attrs with the group path active in WithAttrs
+	for _, ga := range h.attrs {
+		handlerFields = appendAttrFields(handlerFields, replace, ga.groups, ga.attr, 0)
+	}
+
+	// Add attrs derived from the context (worker label, deadline)
+	ctxAttrs := contextAttrs(ctx)
+	for _, a := range ctxAttrs {
+		ctxFields = appendAttrFields(ctxFields, replace, nil, a, 0)
+	}
+	if hooksDue(ctx) {
+		runHooks(r, hookAttrs(r, h.groups, h.attrs, ctxAttrs))
+	}
+
+	if dedupeKeys {
+		// Logical order: With attrs, then call-site attrs, then context attrs
+		dedupeFields(&handlerFields, &recFields, &ctxFields)
+	}
+	fields := [][]attrField{handlerFields, recFields, ctxFields}
+	if !handlerAttrsFirst {
+		fields[0], fields[1] = recFields, handlerFields
+	}
+	if sortAttrs {
+		fields = [][]attrField{sortFields(fields)}
+	}
+
+	format := formatLine
+	if h.logfmt {
+		format = formatLogfmtLine
+	} else if h.indent > 0 {
+		r.Message = strings.Repeat(scopeIndent, h.indent) + r.Message
+	}
+	var err error
+	if box := routeRecord(ctx, r); box != nil {
+		colored := !colorsDisabled && !compact && (colorsForced || box.tty)
+		_, err = writeRouted(box, r.Level, format(r, loc, fields, colored))
+	} else {
+		_, err = h.writer.writeLevel(r.Level, format(r, loc, fields, h.colorsOn()))
+	}
+	if terr := h.writer.writeTees(r.Level, func(colored bool) []byte {
+		return format(r, loc, fields, colored && !colorsDisabled && !compact)
+	}); err == nil {
+		err = terr
+	}
+	return err
+}
+
+// formatLine renders a text record from its parts, with or without colors:
+//
+//	[2025/12/26 15:04:05] LEVEL [source_location] message key=value...
+//	LEVEL message key=value... (compact)
+func formatLine(r slog.Record, loc string, fields [][]attrField, colors bool) []byte {
+	colors = colors && r.Level >= colorMinLevel
+	timeStr := ""
+	switch {
+	case compact:
+	case relativeTime:
+		timeStr = "[" + relativeStamp(r.Time) + "] "
+	case timeFormat != "":
+		timeStr = "[" + recordTime(r.Time).Format(timeFormat) + "] "
+	}
+	levelStr, levelColor := formatLevelWithColor(r.Level, colors)
+	strip := stripControlOn(colors)
 
-	// Get source location from PC
 	source := ""
-	if r.PC != 0 {
-		fs := runtime.CallersFrames([]uintptr{r.PC})
-		f, _ := fs.Next()
-		if f.File != "" {
-			// Extract just the filename, not full path
-			file := f.File
-			if idx := strings.LastIndex(file, "/"); idx >= 0 {
-				file = file[idx+1:]
+	if loc != "" {
+		if strip {
+			loc = stripControl(loc)
+		}
+		loc = fitSource(loc, sourceWidth)
+		if colors && colorSource != "" {
+			source = fmt.Sprintf("%s[%s]%s ", adaptColor(colorSource), loc, colorReset)
+		} else {
+			source = fmt.Sprintf("[%s] ", loc)
+		}
+	}
+
+	msg := r.Message
+	if strip {
+		msg = stripControl(msg)
+	}
+	attrsFirst := attrsPosition == AttrsBefore
+	if attrsFirst {
+		msg = strconv.Quote(msg)
+	} else if maxLineWidth > 0 {
+		header := utf8.RuneCountInString(linePrefix+timeStr) + levelNameWidth + 1
+		if loc != "" {
+			header += utf8.RuneCountInString(loc) + 3 // "[loc] "
+		}
+		msg = fitMessage(msg, header)
+	}
+
+	// Apply level color to the message ONLY for TRACE level
+	// For other levels, message remains default color (only level label is colored)
+	if colors && levelColor != "" && r.Level == LevelTrace {
+		msg = levelColor + msg + colorReset
+	}
+	var content strings.Builder
+	if !attrsFirst {
+		content.WriteString(msg)
+	}
+
+	// Keys and values are colored as separate segments, each followed by a reset.
+	// Column padding is written lazily so lines don't end in spaces.
+	pad := 0
+	var below strings.Builder // Multi-line values, printed under the line
+	writeField := func(f attrField) int {
+		if strip {
+			f.key = stripControl(f.key)
+		}
+		if f.multiline {
+			below.WriteString("\n" + multilineMarker + colorize(f.key, colorKey, colors) + ":")
+			for _, line := range strings.Split(f.value, "\n") {
+				line = strings.TrimRight(line, "\r")
+				if strip {
+					line = stripControl(line)
+				}
+				below.WriteString("\n" + multilineMarker + "  " + line)
+			}
+			return 0
+		}
+		content.WriteString(strings.Repeat(" ", pad))
+		pad = 0
+		content.WriteString(attrSeparator)
+		content.WriteString(colorize(f.key, colorKey, colors))
+		content.WriteString(kvDelimiter)
+		value := colorize(f.value, colorValue, colors)
+		switch {
+		case f.change && colors:
+			value = colorize(f.value, colorError, true) + changeArrow + colorize(f.newValue, defaultColorGreen, true)
+			f.value += changeArrow + f.newValue
+		case f.change:
+			f.value += changeArrowPlain + f.newValue
+			value = f.value
+		case f.color != "":
+			value = colorize(f.value, f.color, colors)
+		}
+		content.WriteString(value)
+		return utf8.RuneCountInString(f.key) + utf8.RuneCountInString(kvDelimiter) + utf8.RuneCountInString(f.value)
+	}
+	if len(attrColumns) > 0 {
+		var cols []*attrField
+		cols, fields = splitColumns(fields)
+		for _, f := range cols {
+			if f == nil {
+				pad += utf8.RuneCountInString(attrSeparator) + attrColumnWidth
+				continue
 			}
-			loc := fmt.Sprintf("%s:%d", file, f.Line)
-			// Pad or truncate to fixed width
-			if len(loc) > sourceWidth {
-				loc = loc[:sourceWidth]
-			} else {
-				loc = fmt.Sprintf("%-*s", sourceWidth, loc)
+			if w := writeField(*f); w < attrColumnWidth {
+				pad += attrColumnWidth - w
 			}
-			if !colorsDisabled && colorSource != "" {
-				source = fmt.Sprintf("%s[%s]%s", colorSource, loc, colorReset)
-			} else {
-				source = fmt.Sprintf("[%s]", loc)
+		}
+	}
+	for _, fs := range fields {
+		for _, f := range fs {
+			writeField(f)
+		}
+	}
+	msgContent := content.String()
+	if attrsFirst {
+		// Attrs first: drop the separator before the first one, add one before the message
+		if attrs := strings.TrimPrefix(msgContent, attrSeparator); attrs != "" {
+			msgContent = attrs + attrSeparator + msg
+		} else {
+			msgContent = msg
+		}
+	}
+
+	// Build final message: [time] LEVEL [source] message
+	return []byte(fmt.Sprintf("%s%s%s %s%s%s%s%s", linePrefix, timeStr, levelStr, source, msgContent, lineSuffix, below.String(), lineTerminator))
+}
+
+var (
+	maxLineWidth int  // Columns for the text header and message (0: unlimited)
+	wrapLines    bool // Wrap long messages instead of truncating them
+)
+
+// minMessageWidth is the least room a message gets, however wide the header
+const minMessageWidth = 10
+
+// SetMaxLineWidth fits the text format's header and message into n columns:
+// longer messages are cut with an ellipsis, or wrapped onto lines indented to
+// the message column with SetWrapMode(true). Attrs are always written in
+// full after the message. A negative n uses the width of the terminal on
+// stdout (or stderr, or $COLUMNS), read once now; 0 (the default) doesn't limit.
+func SetMaxLineWidth(n int) {
+	if n < 0 {
+		n = detectTerminalWidth()
+	}
+	maxLineWidth = n
+}
+
+// SetWrapMode makes SetMaxLineWidth wrap long messages instead of truncating them
+func SetWrapMode(wrap bool) { wrapLines = wrap }
+
+// detectTerminalWidth returns the width of the terminal on stdout or stderr,
+// falling back to $COLUMNS, or 0 if unknown
+func detectTerminalWidth() int {
+	for _, f := range []*os.File{os.Stdout, os.Stderr} {
+		if n := terminalColumns(f); n > 0 {
+			return n
+		}
+	}
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+	return 0
+}
+
+// fitMessage truncates or wraps msg to the columns left of maxLineWidth after
+// a header of width indent. Wrapped lines break at the last space that fits.
+func fitMessage(msg string, indent int) string {
+	avail := max(maxLineWidth-indent, minMessageWidth)
+	runes := []rune(msg)
+	if len(runes) <= avail {
+		return msg
+	}
+	if !wrapLines {
+		return string(runes[:avail-1]) + "…"
+	}
+
+	var b strings.Builder
+	for len(runes) > avail {
+		cut, next := avail, avail
+		for i := avail; i > 0; i-- {
+			if runes[i] == ' ' {
+				cut, next = i, i+1
+				break
 			}
 		}
+		b.WriteString(string(runes[:cut]))
+		b.WriteString("\n" + strings.Repeat(" ", indent))
+		runes = runes[next:]
+	}
+	b.WriteString(string(runes))
+	return b.String()
+}
+
+// multilineMarker starts every continuation line of a multi-line value
+const multilineMarker = "  | "
+
+// multilineAttrs prints values containing newlines below the line
+var multilineAttrs bool
+
+// SetMultilineAttrs prints attr values that contain newlines (stacks from
+// Recover, indented JSON payloads) in the text format on their own lines
+// below the record, each marked with "  | ", instead of quoted on one line:
+//
+//	[2025/12/26 15:04:05] ERROR [main.go:12          ] panic recovered error=boom
+//	  | stack:
+//	  |   main.main
+//	  |   	/app/main.go:12
+//
+// Off by default. logfmt and JSON output keep values on one line.
+func SetMultilineAttrs(enabled bool) { multilineAttrs = enabled }
+
+// rawValue renders an attr value without quoting
+func rawValue(v slog.Value) string {
+	if st, ok := v.Any().(Stack); ok && v.Kind() == slog.KindAny {
+		return st.String()
+	}
+	if v.Kind() == slog.KindString {
+		return v.String()
+	}
+	return fmt.Sprintf("%v", v.Any())
+}
+
+// includeSource enables the source location (LOG_SOURCE)
+var includeSource = true
+
+// SetIncludeSource enables or disables the source location in all formats.
+// When disabled, glogi doesn't capture the caller at all, saving a
+// runtime.Callers call per record in hot paths. LOG_SOURCE=off disables it from env.
+func SetIncludeSource(enabled bool) { includeSource = enabled }
+
+// sourceMinLevel is the lowest level whose records get a source location
+var sourceMinLevel = slog.Level(math.MinInt)
+
+// SetSourceMinLevel captures and shows the source location only for records
+// at or above level, e.g. LevelWarn to skip the runtime.Callers cost on
+// high-volume TRACE/DEBUG lines while keeping it where it matters. By default
+// every level has a source. SetIncludeSource(false) turns it off entirely.
+func SetSourceMinLevel(level slog.Level) { sourceMinLevel = level }
+
+// captureSource reports whether the caller should be captured for a record at lvl
+func captureSource(lvl slog.Level) bool {
+	return includeSource && lvl >= sourceMinLevel
+}
+
+// callerSkip is the number of extra frames skipped when capturing the caller
+var callerSkip int
+
+// SetCallerSkip makes glogi report the caller extra frames further up the
+// stack, for code that wraps glogi in its own helpers. With 0 (the default)
+// the source is the direct caller of the glogi function (Info, Printf,
+// Logger.Info, Timed, ...); a helper that calls glogi.Info directly needs 1,
+// one wrapping such a helper 2. Negative values are treated as 0.
+//
+//	func Info(msg string, args ...any) { glogi.Info(msg, args...) } // SetCallerSkip(1)
+func SetCallerSkip(extra int) {
+	if extra < 0 {
+		extra = 0
+	}
+	callerSkip = extra
+}
+
+// fitSource pads or truncates a source location to exactly width characters.
+// Long locations keep their ":line" suffix and shorten the file name instead
+// (very_long_fi…:123); if even that doesn't fit, the rightmost characters are kept.
+func fitSource(loc string, width int) string {
+	runes := []rune(loc)
+	if len(runes) <= width {
+		return fmt.Sprintf("%-*s", width, loc)
+	}
+	if i := strings.LastIndexByte(loc, ':'); i >= 0 {
+		tail := []rune(loc[i:])
+		if keep := width - len(tail) - 1; keep >= 1 {
+			return string(runes[:keep]) + "…" + string(tail)
+		}
 	}
+	if width <= 0 {
+		return ""
+	}
+	return string(runes[len(runes)-width:])
+}
 
-	// Build message content (will be colorized)
-	msgContent := r.Message
+// unknownSource is shown in the text source column for records without a
+// caller (no PC, e.g. built by hand with slog.NewRecord), so columns stay aligned
+const unknownSource = "-"
+
+// sourceAttrKey is the record attr whose value replaces the source location
+const sourceAttrKey = "_source"
+
+// takeSourceOverride removes a _source attr from r and returns the source to
+// show: its value if present (overridden=true), otherwise the location of
+// r.PC. Callers that log on behalf of something else can name it instead of
+// their own file:line:
+//
+//	log.Info("imported", slog.String("_source", "task:import-csv"))
+func takeSourceOverride(r *slog.Record) (source string, overridden bool) {
+	found := false
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == sourceAttrKey {
+			source, found = a.Value.Resolve().String(), true
+			return false
+		}
+		return true
+	})
+	if !found {
+		if r.Level < sourceMinLevel {
+			return "", false
+		}
+		return sourceLocation(r.PC), false
+	}
 
-	// Add attributes
+	rest := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
 	r.Attrs(func(a slog.Attr) bool {
-		msgContent += fmt.Sprintf(" %s=%v", a.Key, a.Value.Any())
+		if a.Key != sourceAttrKey {
+			rest.AddAttrs(a)
+		}
 		return true
 	})
+	*r = rest
+	return source, true
+}
 
-	// Add handler-level attrs
-	for _, a := range h.attrs {
-		msgContent += fmt.Sprintf(" %s=%v", a.Key, a.Value.Any())
+// sourceLocation resolves a PC to "file:line" (see SetSourceMode),
+// or to the result of the custom source formatter if one is set.
+// Returns an empty string when the PC is unknown.
+func sourceLocation(pc uintptr) string {
+	if !includeSource {
+		return ""
+	}
+	if sourceFormatter != nil && pc != 0 {
+		f, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		if f.File == "" {
+			return ""
+		}
+		return sourceFormatter(f.File, f.Line, f.Function)
+	}
+	file, line, fn := sourceFileLine(pc)
+	if file == "" {
+		return ""
 	}
+	if showFunction && fn != "" {
+		return fmt.Sprintf("%s:%d %s", file, line, fn)
+	}
+	return fmt.Sprintf("%s:%d", file, line)
+}
 
-	// Apply level color to message content ONLY for TRACE level
-	// For other levels, message remains default color (only level label is colored)
-	if !colorsDisabled && levelColor != "" && r.Level == LevelTrace {
-		msgContent = fmt.Sprintf("%s%s%s", levelColor, msgContent, colorReset)
+// showFunction adds the caller's function name to the source (LOG_SOURCE_FUNC)
+var showFunction bool
+
+// SetShowFunction adds the short name of the calling function to the source
+// location, [handler.go:42 handlePayment] (methods as (*Server).handle), and
+// to ECS log.origin.function. Off by default; consider a wider source column
+// (SetSourceWidth), as long locations are truncated to fit.
+func SetShowFunction(enabled bool) { showFunction = enabled }
+
+// shortFunction strips the package path from a qualified function name:
+// github.com/acme/api.(*Server).handle becomes (*Server).handle
+func shortFunction(fn string) string {
+	if i := strings.LastIndexByte(fn, '/'); i >= 0 {
+		fn = fn[i+1:]
+	}
+	if i := strings.IndexByte(fn, '.'); i >= 0 {
+		fn = fn[i+1:]
 	}
+	return fn
+}
 
-	// Build final message: [time] LEVEL [source] message
-	msg := fmt.Sprintf("[%s] %s %s %s\n", timeStr, levelStr, source, msgContent)
+// SourceMode controls how much of the file path the source location shows
+type SourceMode string
 
-	_, err := h.writer.Write([]byte(msg))
-	return err
+// Source modes
+const (
+	SourceFilename    SourceMode = "filename"     // handler.go:42
+	SourcePackageFile SourceMode = "package/file" // glogi/handler.go:42
+	SourceFull        SourceMode = "full"         // /src/glogi/handler.go:42
+)
+
+// sourceMode is the active source mode (LOG_SOURCE_MODE)
+var sourceMode = SourceFilename
+
+// SetSourceMode sets how source locations show the file: the file name only
+// (default), prefixed with its directory to tell apart files with the same name
+// in different packages, or the full path. Unknown modes select the default.
+// Consider raising the source width (SetSourceWidth) for the longer modes.
+func SetSourceMode(mode SourceMode) {
+	switch mode {
+	case SourcePackageFile, SourceFull:
+		sourceMode = mode
+	default:
+		sourceMode = SourceFilename
+	}
+}
+
+// sourceFileLine resolves a PC to its file (shown according to the source mode),
+// line and short function name. Returns an empty file when the PC is unknown.
+func sourceFileLine(pc uintptr) (string, int, string) {
+	if pc == 0 || !includeSource {
+		return "", 0, ""
+	}
+	fs := runtime.CallersFrames([]uintptr{pc})
+	f, _ := fs.Next()
+	if f.File == "" {
+		return "", 0, ""
+	}
+	file := f.File
+	switch sourceMode {
+	case SourceFull:
+	case SourcePackageFile:
+		// Keep the last directory: pkg/file.go
+		if idx := strings.LastIndex(file, "/"); idx >= 0 {
+			if dir := strings.LastIndex(file[:idx], "/"); dir >= 0 {
+				file = file[dir+1:]
+			}
+		}
+	default:
+		// Extract just the filename, not full path
+		if idx := strings.LastIndex(file, "/"); idx >= 0 {
+			file = file[idx+1:]
+		}
+	}
+	return file, f.Line, shortFunction(f.Function)
+}
+
+// levelName returns the display name for a level (TRACE, DEBUG, ..., PANIC or
+// a registered level); other values take the name of the level they round to
+func levelName(l slog.Level) string {
+	r := registeredLevel(l)
+	if cl, ok := customLevelValues[r]; ok {
+		return cl.name
+	}
+	switch r {
+	case LevelTrace:
+		return "TRACE"
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	case LevelFatal:
+		return "FATAL"
+	default:
+		return "PANIC"
+	}
+}
+
+// allLevels lists the levels with a display name, lowest first
+var allLevels = []slog.Level{LevelTrace, LevelDebug, LevelInfo, LevelWarn, LevelError, LevelFatal, LevelPanic}
+
+var (
+	levelNames     map[slog.Level]string // Custom display names for the text handler
+	levelNameWidth = 5                   // Width of the level column
+)
+
+// SetLevelNames overrides the level names shown by the text handler, e.g.
+// single letters or "WARNING". Levels missing from names keep their default
+// name; levels between the standard ones use the name of the level they are
+// shown as (a level of -6 uses the TRACE name, see SetLevelRoundUp). The level
+// column is padded to the longest name. Call with nil to restore the defaults.
+// Structured output keeps the standard names.
+//
+//	log.SetLevelNames(map[slog.Level]string{
+//	    log.LevelTrace: "t", log.LevelDebug: "d", log.LevelInfo: "i",
+//	    log.LevelWarn: "w", log.LevelError: "e", log.LevelFatal: "f", log.LevelPanic: "p",
+//	})
+func SetLevelNames(names map[slog.Level]string) {
+	custom := make(map[slog.Level]string, len(names))
+	for l, name := range names {
+		custom[l] = name
+	}
+	levelNames = custom
+	updateLevelNameWidth()
+}
+
+// LevelDisplay controls how the text and logfmt formats label levels
+type LevelDisplay string
+
+// Level displays
+const (
+	LevelDisplayName   LevelDisplay = "name"   // INFO
+	LevelDisplayNumber LevelDisplay = "number" // 0
+	LevelDisplayBoth   LevelDisplay = "both"   // INFO(0)
+)
+
+// levelDisplay is the active level display (LOG_LEVEL_DISPLAY)
+var levelDisplay = LevelDisplayName
+
+// SetLevelDisplay sets whether the text and logfmt formats label levels by
+// name (default), by their slog number (-8, 0, 4, 12) for tools that filter
+// on numeric severity, or both (INFO(0)). Unknown modes select the default.
+// Colors still follow the level.
+func SetLevelDisplay(mode LevelDisplay) {
+	switch mode {
+	case LevelDisplayNumber, LevelDisplayBoth:
+		levelDisplay = mode
+	default:
+		levelDisplay = LevelDisplayName
+	}
+	updateLevelNameWidth()
+}
+
+// levelLabel returns the level label shown by the text and logfmt formats
+func levelLabel(l slog.Level) string {
+	switch levelDisplay {
+	case LevelDisplayNumber:
+		return strconv.Itoa(int(l))
+	case LevelDisplayBoth:
+		return displayLevelName(l) + "(" + strconv.Itoa(int(l)) + ")"
+	default:
+		return displayLevelName(l)
+	}
+}
+
+// displayLevelName returns the text handler's name for a level: the custom
+// name if one is set for the level (or the level it rounds to), otherwise the
+// default name
+func displayLevelName(l slog.Level) string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+	if name, ok := levelNames[registeredLevel(l)]; ok {
+		return name
+	}
+	return levelName(l)
+}
+
+// colorize wraps s in color and a reset when colors are on and color is set
+func colorize(s, color string, on bool) string {
+	if !on || color == "" {
+		return s
+	}
+	return adaptColor(color) + s + colorReset
+}
+
+// colorForLevel returns the configured color for a level
+func colorForLevel(l slog.Level) string {
+	if fn := colorFunc; fn != nil {
+		return parseColor(fn(l))
+	}
+	if cl, ok := customLevelValues[registeredLevel(l)]; ok && cl.color != "" {
+		return cl.color
+	}
+	switch standardLevel(l) {
+	case LevelTrace:
+		return colorTrace
+	case LevelDebug:
+		return colorDebug
+	case LevelInfo:
+		return colorInfo
+	case LevelWarn:
+		return colorWarn
+	default:
+		return colorError
+	}
+}
+
+// attrField is a rendered attr: the key with its group path, and the formatted value
+type attrField struct {
+	key       string
+	value     string
+	color     string // Overrides the value color (slow durations)
+	multiline bool   // value is raw text to print below the line (SetMultilineAttrs)
+	change    bool   // Change attr: value is the old value, newValue the new one
+	newValue  string
+}
+
+// appendAttrFields renders an attr as key=value fields, the key prefixed by
+// the group path. Group values expand recursively (user.id=42 user.name=bob);
+// empty groups are dropped. replace, if set, is applied to every non-group
+// attr; an empty key drops the attr.
+func appendAttrFields(dst []attrField, replace func([]string, slog.Attr) slog.Attr, groups []string, a slog.Attr, depth int) []attrField {
+	c, isChange := a.Value.Any().(change)
+	a.Value = a.Value.Resolve()
+	if replace != nil && a.Value.Kind() != slog.KindGroup {
+		a = replace(groups, a)
+		a.Value = a.Value.Resolve()
+	}
+	a = redactAttr(a)
+	if isChange && (a.Value.Kind() != slog.KindGroup || a.Key == "") {
+		isChange = false // Replaced or redacted: render what's left
+	}
+	if a.Value.Kind() == slog.KindGroup && !isChange {
+		if depth >= maxAttrDepth {
+			a.Value = slog.StringValue(maxDepthValue)
+		} else {
+			if a.Key != "" {
+				groups = append(groups[:len(groups):len(groups)], a.Key)
+			}
+			for _, ga := range a.Value.Group() {
+				dst = appendAttrFields(dst, replace, groups, ga, depth+1)
+			}
+			return dst
+		}
+	}
+	if a.Key == "" {
+		return dst
+	}
+	if isChange {
+		return append(dst, changeField(groupPrefix(groups)+a.Key, c))
+	}
+	f := attrField{key: groupPrefix(groups) + a.Key, value: formatValue(a.Value)}
+	if multilineAttrs {
+		if raw := rawValue(a.Value); strings.Contains(raw, "\n") {
+			f.value, f.multiline = truncateValue(strings.TrimRight(raw, "\n")), true
+		}
+	}
+	if d, ok := a.Value.Any().(jsonDump); ok && a.Value.Kind() == slog.KindAny {
+		f.value, f.multiline = d.String(), true // DebugJSON: always below the line
+	}
+	if a.Value.Kind() == slog.KindDuration {
+		f.color = slowColor(a.Value.Duration())
+	}
+	dst = append(dst, f)
+	if expandErrors && a.Value.Kind() == slog.KindAny {
+		if err, ok := a.Value.Any().(error); ok {
+			dst = appendErrorCauses(dst, f.key, err)
+		}
+	}
+	return dst
+}
+
+// maxErrorCauses caps how many wrapped errors SetExpandErrors lists, so a
+// cyclic Unwrap chain can't loop forever
+const maxErrorCauses = 10
+
+// expandErrors lists the wrapped causes of error attrs in text output
+var expandErrors bool
+
+// SetExpandErrors makes the text output follow errors.Unwrap on error attrs
+// and print each wrapped error as its own field:
+//
+//	err="load config: open app.yaml: no such file" err.cause="open app.yaml: no such file" err.cause.cause="no such file"
+//
+// At most 10 causes are listed. Off by default (a single err=... field).
+func SetExpandErrors(enabled bool) { expandErrors = enabled }
+
+// appendErrorCauses appends a key.cause field for each error wrapped by err
+func appendErrorCauses(dst []attrField, key string, err error) []attrField {
+	for i := 0; i < maxErrorCauses; i++ {
+		if err = errors.Unwrap(err); err == nil {
+			break
+		}
+		key += ".cause"
+		dst = append(dst, attrField{key: key, value: formatValue(slog.StringValue(err.Error()))})
+	}
+	return dst
+}
+
+// dedupeKeys makes the last value of a repeated key win within a record
+var dedupeKeys bool
+
+// SetDedupeKeys drops repeated attr keys within a record so each key appears
+// once, for consumers that assume unique keys. The last value wins, in the
+// order With attrs, call-site attrs, context attrs, so a call-site user=2
+// overrides a bound user=1. Keys are compared with their group path (http.id
+// and db.id are distinct). Off by default.
+func SetDedupeKeys(enabled bool) { dedupeKeys = enabled }
+
+// sortAttrs writes text and logfmt attrs sorted by key (LOG_SORT_ATTRS)
+var sortAttrs bool
+
+// SetSortAttrs sorts the attrs of each text or logfmt record by key,
+// including the group path, so output doesn't depend on the order attrs were
+// added in, e.g. for golden-file tests. Grouped attrs stay together
+// (http.method, http.path); equal keys keep their order. Off by default.
+func SetSortAttrs(enabled bool) { sortAttrs = enabled }
+
+// AttrsPosition is where the text format writes attrs relative to the message
+type AttrsPosition string
+
+// Attr positions
+const (
+	AttrsAfter  AttrsPosition = "after"  // INFO [main.go:18] request failed user_id=5 method=GET
+	AttrsBefore AttrsPosition = "before" // INFO [main.go:18] user_id=5 method=GET "request failed"
+)
+
+// attrsPosition is the active attr position (LOG_ATTRS_POSITION)
+var attrsPosition = AttrsAfter
+
+// SetAttrsPosition sets whether the text format writes attrs after the message
+// (default) or before it, for layouts and parsers that expect the key=value
+// context first. Before the attrs, the message is quoted so the boundary
+// between attrs and free text is unambiguous, and SetMaxLineWidth doesn't
+// cut or wrap it. logfmt and JSON output are unaffected.
+func SetAttrsPosition(pos AttrsPosition) {
+	if pos == AttrsBefore {
+		attrsPosition = AttrsBefore
+	} else {
+		attrsPosition = AttrsAfter
+	}
+}
+
+// sortFields returns the fields of all lists in one list sorted by key
+func sortFields(lists [][]attrField) []attrField {
+	var all []attrField
+	for _, l := range lists {
+		all = append(all, l...)
+	}
+	slices.SortStableFunc(all, func(a, b attrField) int { return strings.Compare(a.key, b.key) })
+	return all
+}
+
+// dedupeFields removes all but the last occurrence of each key across lists,
+// which are given in precedence order (later lists win)
+func dedupeFields(lists ...*[]attrField) {
+	type pos struct{ list, idx int }
+	last := make(map[string]pos)
+	for li, l := range lists {
+		for i, f := range *l {
+			last[f.key] = pos{li, i}
+		}
+	}
+	for li, l := range lists {
+		kept := (*l)[:0]
+		for i, f := range *l {
+			if last[f.key] == (pos{li, i}) {
+				kept = append(kept, f)
+			}
+		}
+		*l = kept
+	}
+}
+
+// formatValue renders an attr value, quoting it (strconv.Quote) when it is
+// empty or contains spaces, quotes, '=' or non-printable characters, so the
+// output stays logfmt-parseable. Stacks are left unquoted for readability.
+func formatValue(v slog.Value) string {
+	var s string
+	switch v.Kind() {
+	case slog.KindDuration:
+		return formatDuration(v.Duration())
+	case slog.KindTime:
+		s = formatAttrTime(v.Time())
+	default:
+		if st, ok := v.Any().(Stack); ok {
+			return st.String()
+		}
+		if isNilValue(v.Any()) {
+			s = nilText
+		} else {
+			s = truncateValue(fmt.Sprintf("%v", v.Any()))
+		}
+	}
+	if needsQuoting(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// nilText is how nil attr values render in text and logfmt output
+var nilText = "<nil>"
+
+// SetNilText sets how nil values (a nil interface, pointer, func or channel)
+// render in the text and logfmt output, "<nil>" by default, e.g. "null" or
+// "-" for parsers that choke on <nil>. Empty strings are always written as
+// "", so they can't be mistaken for a missing value. JSON output uses null.
+func SetNilText(text string) { nilText = text }
+
+// isNilValue reports whether v is nil or a nil pointer, func or channel
+func isNilValue(v any) bool {
+	if v == nil {
+		return true
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Pointer, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// maxAttrValueLen caps attr values, in runes (0: unlimited)
+var maxAttrValueLen int
+
+// SetMaxAttrValueLen truncates attr values longer than n runes, so a stray
+// blob (a base64 payload, a response body) can't produce a multi-megabyte
+// line. The kept prefix is followed by a marker with the number of bytes cut:
+//
+//	body="PGh0bWw+PGhlYWQ+…(truncated 1048012 bytes)"
+//
+// It applies to text and logfmt values and to JSON string and error values.
+// 0 (the default) doesn't limit.
+func SetMaxAttrValueLen(n int) { maxAttrValueLen = max(n, 0) }
+
+// truncateValue cuts s to maxAttrValueLen runes plus a truncation marker
+func truncateValue(s string) string {
+	if maxAttrValueLen <= 0 || len(s) <= maxAttrValueLen {
+		return s
+	}
+	i, n := 0, 0
+	for i < len(s) && n < maxAttrValueLen {
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		n++
+	}
+	if i == len(s) {
+		return s
+	}
+	return fmt.Sprintf("%s…(truncated %d bytes)", s[:i], len(s)-i)
+}
+
+// formatDuration renders d with about three significant digits after the
+// unit (1.235s, 12.346ms) instead of full nanosecond precision
+func formatDuration(d time.Duration) string {
+	abs := d
+	if abs < 0 {
+		abs = -abs
+	}
+	switch {
+	case abs >= time.Minute:
+		d = d.Round(time.Second)
+	case abs >= time.Second:
+		d = d.Round(time.Millisecond)
+	case abs >= time.Millisecond:
+		d = d.Round(time.Microsecond)
+	}
+	return d.String()
 }
 
-func (h *ColoredHandler) formatLevelWithColor(l slog.Level) (string, string) {
-	var name string
-	var color string
+// slowThreshold colors durations at or above it (LOG_SLOW_THRESHOLD, 0 = off)
+var slowThreshold time.Duration
 
+// SetSlowThreshold colors duration attrs in the text output by how slow they
+// are: at or above d in the WARN color, at or above 2*d in the ERROR color.
+// 0 disables it (the default).
+//
+//	log.SetSlowThreshold(200 * time.Millisecond)
+//	log.Info("query", "duration", elapsed) // duration=512.3ms in red
+func SetSlowThreshold(d time.Duration) { slowThreshold = d }
+
+// slowColor returns the color for a duration under the slow threshold, or ""
+func slowColor(d time.Duration) string {
 	switch {
-	case l <= LevelTrace:
-		name = "TRACE"
-		color = colorTrace
-	case l <= LevelDebug:
-		name = "DEBUG"
-		color = colorDebug
-	case l <= LevelInfo:
-		name = "INFO"
-		color = colorInfo
-	case l <= LevelWarn:
-		name = "WARN"
-		color = colorWarn
-	case l <= LevelError:
-		name = "ERROR"
-		color = colorError
-	case l <= LevelFatal:
-		name = "FATAL"
-		color = colorError
+	case slowThreshold <= 0 || d < slowThreshold:
+		return ""
+	case d >= 2*slowThreshold:
+		return colorError
 	default:
-		name = "PANIC"
-		color = colorError
+		return colorWarn
+	}
+}
+
+// needsQuoting reports whether a value must be quoted in key=value output
+func needsQuoting(s string) bool {
+	if s == "" {
+		return true
 	}
+	for _, r := range s {
+		if r == ' ' || r == '=' || r == '"' || r == utf8.RuneError || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return strings.Contains(s, attrSeparator) || strings.Contains(s, kvDelimiter)
+}
 
-	// Fixed width: 5 characters
-	paddedName := fmt.Sprintf("%-5s", name)
+// formatLevelWithColor returns the padded level label (colored if colors is
+// set) and the level's color ("" when uncolored)
+func formatLevelWithColor(l slog.Level, colors bool) (string, string) {
+	name := levelLabel(l)
+	color := adaptColor(colorForLevel(l))
 
-	if colorsDisabled || color == "" {
+	// Fixed width: the longest level name (5 by default)
+	paddedName := fmt.Sprintf("%-*s", levelNameWidth, name)
+
+	if !colors || color == "" {
 		return paddedName, ""
 	}
 	return fmt.Sprintf("%s%s%s", color, paddedName, colorReset), color
 }
 
+// handlerAttrs returns the attrs added with WithAttrs, without group paths
+func (h *ColoredHandler) handlerAttrs() []slog.Attr {
+	attrs := make([]slog.Attr, len(h.attrs))
+	for i, ga := range h.attrs {
+		attrs[i] = ga.attr
+	}
+	return attrs
+}
+
+// withLevel returns a copy of the handler with its own minimum level
+func (h *ColoredHandler) withLevel(lv *slog.LevelVar) slog.Handler {
+	h2 := *h
+	h2.level = lv
+	return &h2
+}
+
+// withWriter returns a copy of the handler writing to w
+func (h *ColoredHandler) withWriter(w io.Writer) slog.Handler {
+	// Output ends up on the original destination, so keep its terminal detection
+	out := &outputRef{}
+	out.storeAs(w, h.writer.IsTerminal())
+	out.tees.Store(h.writer.tees.Load())
+	return &ColoredHandler{
+		level:       h.level,
+		writer:      out,
+		attrs:       h.attrs,
+		groups:      h.groups,
+		replaceAttr: h.replaceAttr,
+		logfmt:      h.logfmt,
+		indent:      h.indent,
+	}
+}
+
+// output returns the handler's writer
+func (h *ColoredHandler) output() io.Writer { return h.writer }
+
+// setOutput swaps the writer for this handler and all handlers sharing it
+func (h *ColoredHandler) setOutput(w io.Writer) { h.writer.Store(w) }
+
+// setErrorOutput sets the WARN+ destination for this handler and all handlers sharing its writer
+func (h *ColoredHandler) setErrorOutput(w io.Writer) { h.writer.storeError(w) }
+
+// addOutput adds an extra destination for this handler and all handlers sharing its writer
+func (h *ColoredHandler) addOutput(w io.Writer, colored bool) { h.writer.addTee(w, colored) }
+
+// withIndent returns a copy of the handler indenting messages one level deeper
+func (h *ColoredHandler) withIndent() slog.Handler {
+	h2 := *h
+	h2.indent++
+	return &h2
+}
+
+// WithReplaceAttr returns a copy of the handler using fn instead of the global
+// SetReplaceAttr hook
+func (h *ColoredHandler) WithReplaceAttr(fn func(groups []string, a slog.Attr) slog.Attr) *ColoredHandler {
+	h2 := *h
+	h2.replaceAttr = fn
+	return &h2
+}
+
 func (h *ColoredHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 	return &ColoredHandler{
-		level:  h.level,
-		writer: h.writer,
-		attrs:  append(h.attrs, attrs...),
-		groups: h.groups,
+		level:       h.level,
+		writer:      h.writer,
+		attrs:       appendGroupedAttrs(h.attrs, h.groups, attrs),
+		groups:      h.groups,
+		replaceAttr: h.replaceAttr,
+		logfmt:      h.logfmt,
+		indent:      h.indent,
 	}
 }
 
 func (h *ColoredHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	groups := make([]string, 0, len(h.groups)+1)
+	groups = append(groups, h.groups...)
 	return &ColoredHandler{
-		level:  h.level,
-		writer: h.writer,
-		attrs:  h.attrs,
-		groups: append(h.groups, name),
+		level:       h.level,
+		writer:      h.writer,
+		attrs:       h.attrs,
+		groups:      append(groups, name),
+		replaceAttr: h.replaceAttr,
+		logfmt:      h.logfmt,
+		indent:      h.indent,
 	}
 }
 
 // Ensure ColoredHandler implements slog.Handler
 var _ slog.Handler = (*ColoredHandler)(nil)
+
+// firstGroup returns the outermost group name ("" without groups)
+func (h *ColoredHandler) firstGroup() string {
+	if len(h.groups) == 0 {
+		return ""
+	}
+	return h.groups[0]
+}
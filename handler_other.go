@@ -0,0 +1,8 @@
+//go:build !windows
+
+package glogi
+
+import "os"
+
+// enableVirtualTerminal is a no-op: terminals outside Windows handle ANSI escapes
+func enableVirtualTerminal(f *os.File) bool { return true }
@@ -0,0 +1,286 @@
+package glogi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLineTerminator(t *testing.T) {
+	old := lineTerminator
+	SetLineTerminator("\x00")
+	t.Cleanup(func() { lineTerminator = old })
+
+	handlers := map[string]func(w io.Writer, lv *slog.LevelVar) slog.Handler{
+		"text":   func(w io.Writer, lv *slog.LevelVar) slog.Handler { return NewColoredHandler(w, lv) },
+		"json":   func(w io.Writer, lv *slog.LevelVar) slog.Handler { return NewJSONHandler(w, lv) },
+		"ecs":    func(w io.Writer, lv *slog.LevelVar) slog.Handler { return NewECSHandler(w, lv) },
+		"gcp":    func(w io.Writer, lv *slog.LevelVar) slog.Handler { return NewGCPHandler(w, lv) },
+		"logfmt": func(w io.Writer, lv *slog.LevelVar) slog.Handler { return NewLogfmtHandler(w, lv) },
+	}
+	for name, newHandler := range handlers {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := slog.New(newHandler(&buf, &slog.LevelVar{}))
+			l.Info("first", "k", 1)
+			l.Info("second")
+
+			out := buf.String()
+			if strings.Contains(out, "\n") {
+				t.Errorf("output contains a newline: %q", out)
+			}
+			records := strings.Split(out, "\x00")
+			if len(records) != 3 || records[2] != "" {
+				t.Fatalf("want 2 NUL-terminated records, got %q", out)
+			}
+			if !strings.Contains(records[0], "first") || !strings.Contains(records[1], "second") {
+				t.Errorf("records = %q", records[:2])
+			}
+		})
+	}
+}
+
+// overlapWriter records its writes and counts those that overlapped another one
+type overlapWriter struct {
+	buf      bytes.Buffer
+	inFlight atomic.Int32
+	overlaps atomic.Int32
+}
+
+func (w *overlapWriter) Write(p []byte) (int, error) {
+	if w.inFlight.Add(1) > 1 {
+		w.overlaps.Add(1)
+	}
+	defer w.inFlight.Add(-1)
+	return w.buf.Write(p)
+}
+
+func TestConcurrentLoggingKeepsLinesWhole(t *testing.T) {
+	captureOutput(t, "text")
+	w := &overlapWriter{}
+	SetOutput(w)
+
+	const goroutines, lines = 100, 1000
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < lines; i++ {
+				Info("line", "g", g, "i", i)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if n := w.overlaps.Load(); n > 0 {
+		t.Errorf("%d writes overlapped", n)
+	}
+	whole := regexp.MustCompile(`^\[.+\] INFO .*\] line g=\d+ i=\d+$`)
+	got := strings.Split(strings.TrimSuffix(w.buf.String(), "\n"), "\n")
+	if len(got) != goroutines*lines {
+		t.Fatalf("got %d lines, want %d", len(got), goroutines*lines)
+	}
+	for _, line := range got {
+		if !whole.MatchString(line) {
+			t.Fatalf("torn line %q", line)
+		}
+	}
+}
+
+func TestFitSource(t *testing.T) {
+	tests := []struct {
+		loc   string
+		width int
+		want  string
+	}{
+		{"main.go:12", 20, "main.go:12          "},
+		{"exactly_twenty.go:12", 20, "exactly_twenty.go:12"},
+		{"very_long_filename_handler.go:123", 20, "very_long_filen…:123"},
+		{"a_much_much_much_longer_file_name_for_the_source_column.go:45678", 20, "a_much_much_m…:45678"},
+		{"handler.go:123456789", 8, "23456789"},
+		{"no_line_number_at_all.go", 10, "_at_all.go"},
+		{"main.go:1", 0, ""},
+	}
+	for _, tt := range tests {
+		got := fitSource(tt.loc, tt.width)
+		if got != tt.want {
+			t.Errorf("fitSource(%q, %d) = %q, want %q", tt.loc, tt.width, got, tt.want)
+		}
+		if n := len([]rune(got)); n != tt.width {
+			t.Errorf("fitSource(%q, %d) is %d characters wide", tt.loc, tt.width, n)
+		}
+	}
+}
+
+// wrappedInfo wraps glogi one level deep, as in SetCallerSkip's example
+func wrappedInfo(msg string) { Info(msg) }
+
+func TestCallerSkip(t *testing.T) {
+	buf := captureOutput(t, "text")
+	SetCallerSkip(1)
+	t.Cleanup(func() { SetCallerSkip(0) })
+
+	_, _, line, _ := runtime.Caller(0)
+	wrappedInfo("wrapped") // The reported caller
+	want := fmt.Sprintf("[handler_test.go:%d ", line+1)
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("output = %q, want source %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	SetCallerSkip(0)
+	wrappedInfo("unwrapped")
+	_, _, line, _ = runtime.Caller(0)
+	if want := fmt.Sprintf("[handler_test.go:%d ", line-1); strings.Contains(buf.String(), want) {
+		t.Errorf("without skip the source should be the wrapper: %q", buf.String())
+	}
+}
+
+func TestTimeAttr(t *testing.T) {
+	oldLocal, oldFormat, oldUTC, oldAttrFormat := time.Local, timeFormat, timeUTC, attrTimeFormat
+	t.Cleanup(func() {
+		time.Local, timeFormat, timeUTC, attrTimeFormat = oldLocal, oldFormat, oldUTC, oldAttrFormat
+	})
+	time.Local = time.FixedZone("UTC+3", 3*60*60)
+	SetTimeFormat("2006/01/02 15:04:05")
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		utc        bool
+		attrFormat string
+		want       string
+	}{
+		{"local", false, "", `at="2025/01/02 06:04:05"`},
+		{"utc", true, "", `at="2025/01/02 03:04:05"`},
+		{"local attr format", false, time.RFC3339, `at=2025-01-02T06:04:05+03:00`},
+		{"utc attr format", true, time.RFC3339, `at=2025-01-02T03:04:05Z`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetTimeUTC(tt.utc)
+			SetAttrTimeFormat(tt.attrFormat)
+			var buf bytes.Buffer
+			slog.New(NewColoredHandler(&buf, &slog.LevelVar{})).Info("scheduled", "at", at)
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("output = %q, want %s", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestAttrOrder(t *testing.T) {
+	t.Cleanup(func() { SetAttrOrder(true) })
+	ctx := WithWorkerLabel(context.Background(), "w1")
+	tests := []struct {
+		handlerFirst bool
+		want         string
+	}{
+		{true, "hit a=1 b=2 c=3 worker=w1"},
+		{false, "hit c=3 a=1 b=2 worker=w1"},
+	}
+	for _, tt := range tests {
+		for _, logfmt := range []bool{false, true} {
+			SetAttrOrder(tt.handlerFirst)
+			var buf bytes.Buffer
+			var h slog.Handler = NewColoredHandler(&buf, &slog.LevelVar{})
+			if logfmt {
+				h = NewLogfmtHandler(&buf, &slog.LevelVar{})
+			}
+			slog.New(h).With("a", 1).With("b", 2).InfoContext(ctx, "hit", "c", 3)
+
+			want := tt.want
+			if logfmt {
+				want = `msg=hit a=1 b=2 c=3 worker=w1`
+				if !tt.handlerFirst {
+					want = `msg=hit c=3 a=1 b=2 worker=w1`
+				}
+			}
+			if !strings.HasSuffix(strings.TrimSuffix(buf.String(), "\n"), want) {
+				t.Errorf("handlerFirst=%v logfmt=%v: output = %q, want suffix %q", tt.handlerFirst, logfmt, buf.String(), want)
+			}
+		}
+	}
+}
+
+func TestNilAndEmptyValues(t *testing.T) {
+	t.Cleanup(func() { SetNilText("<nil>") })
+	var nilPtr *int
+	var nilErr error
+	tests := []struct {
+		name    string
+		nilText string
+		value   any
+		want    string
+	}{
+		{"nil interface", "<nil>", nil, "val=<nil>"},
+		{"typed nil pointer", "<nil>", nilPtr, "val=<nil>"},
+		{"nil error", "<nil>", nilErr, "val=<nil>"},
+		{"empty string", "<nil>", "", `val=""`},
+		{"custom nil interface", "null", nil, "val=null"},
+		{"custom typed nil pointer", "null", nilPtr, "val=null"},
+		{"custom nil text with space", "no value", nil, `val="no value"`},
+		{"empty string with custom nil text", "null", "", `val=""`},
+	}
+	for _, tt := range tests {
+		for _, logfmt := range []bool{false, true} {
+			SetNilText(tt.nilText)
+			var buf bytes.Buffer
+			var h slog.Handler = NewColoredHandler(&buf, &slog.LevelVar{})
+			if logfmt {
+				h = NewLogfmtHandler(&buf, &slog.LevelVar{})
+			}
+			slog.New(h).Info("x", "val", tt.value)
+			if got := strings.TrimSuffix(buf.String(), "\n"); !strings.HasSuffix(got, " "+tt.want) {
+				t.Errorf("%s (logfmt=%v): output = %q, want suffix %s", tt.name, logfmt, got, tt.want)
+			}
+		}
+	}
+}
+
+func BenchmarkDebugSource(b *testing.B) {
+	captureOutput(b, "text")
+	SetOutput(io.Discard)
+	b.Cleanup(func() { SetSourceMinLevel(slog.Level(math.MinInt)) })
+	for _, bc := range []struct {
+		name string
+		min  slog.Level
+	}{
+		{"all levels", slog.Level(math.MinInt)},
+		{"WARN and above", LevelWarn},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			SetSourceMinLevel(bc.min)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				Debug("tick", "i", i)
+			}
+		})
+	}
+}
+
+func TestSourceMinLevelSavesAllocs(t *testing.T) {
+	captureOutput(t, "text")
+	SetOutput(io.Discard)
+	t.Cleanup(func() { SetSourceMinLevel(slog.Level(math.MinInt)) })
+
+	debug := func() { Debug("tick", "i", 1) }
+	SetSourceMinLevel(slog.Level(math.MinInt))
+	withSource := testing.AllocsPerRun(100, debug)
+	SetSourceMinLevel(LevelWarn)
+	withoutSource := testing.AllocsPerRun(100, debug)
+	if withoutSource >= withSource {
+		t.Errorf("allocs per DEBUG record: %v without source, %v with", withoutSource, withSource)
+	}
+}
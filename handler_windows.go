@@ -0,0 +1,30 @@
+//go:build windows
+
+package glogi
+
+import (
+	"os"
+	"syscall"
+)
+
+// enableVirtualTerminalProcessing is the console mode flag that makes the
+// Windows console interpret ANSI escape sequences (Windows 10+)
+const enableVirtualTerminalProcessing = 0x0004
+
+var procSetConsoleMode = syscall.NewLazyDLL("kernel32.dll").NewProc("SetConsoleMode")
+
+// enableVirtualTerminal turns on ANSI escape processing for a console.
+// It returns false if f is not a console or the console doesn't support it
+// (cmd.exe before Windows 10), in which case colors stay off by default.
+func enableVirtualTerminal(f *os.File) bool {
+	h := syscall.Handle(f.Fd())
+	var mode uint32
+	if err := syscall.GetConsoleMode(h, &mode); err != nil {
+		return false
+	}
+	if mode&enableVirtualTerminalProcessing != 0 {
+		return true
+	}
+	r, _, _ := procSetConsoleMode.Call(uintptr(h), uintptr(mode|enableVirtualTerminalProcessing))
+	return r != 0
+}
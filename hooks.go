@@ -0,0 +1,75 @@
+package glogi
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Hook is called for every record that passes the level check
+type Hook func(level slog.Level, msg string, attrs []slog.Attr)
+
+// hooks are the functions registered with AddHook
+var hooks []Hook
+
+// AddHook registers fn to be called for every emitted record, e.g. to count
+// records per level or forward errors to an alerting service:
+//
+//	log.AddHook(func(level slog.Level, msg string, attrs []slog.Attr) {
+//	    logLines.WithLabelValues(level.String()).Inc()
+//	})
+//
+// attrs holds the record's attrs followed by handler-level (With) and context
+// attrs; attrs under a group are nested in group attrs. It is a fresh slice per
+// call, so hooks can't change what is logged.
+//
+// Hooks run synchronously on the logging goroutine, in registration order,
+// before the record is written: keep them fast or hand the work off to your own
+// goroutine. Register hooks at startup, before logging.
+func AddHook(fn Hook) {
+	hooks = append(hooks, fn)
+}
+
+// hooksDue reports whether a handler should run the hooks for the record of
+// ctx: never for Format, and once for a record fanned out to several handlers
+func hooksDue(ctx context.Context) bool {
+	if len(hooks) == 0 || previewing(ctx) {
+		return false
+	}
+	if d := fanoutAdmit(ctx); d != nil {
+		if d.hooked {
+			return false
+		}
+		d.hooked = true
+	}
+	return true
+}
+
+// runHooks calls the registered hooks for a record
+func runHooks(r slog.Record, attrs []slog.Attr) {
+	for _, fn := range hooks {
+		fn(r.Level, r.Message, append([]slog.Attr(nil), attrs...))
+	}
+}
+
+// nestAttr wraps a in group attrs for the given group path (a.b.key)
+func nestAttr(groups []string, a slog.Attr) slog.Attr {
+	for i := len(groups) - 1; i >= 0; i-- {
+		a = slog.Attr{Key: groups[i], Value: slog.GroupValue(a)}
+	}
+	return a
+}
+
+// hookAttrs collects a record's attrs for hooks: record attrs under the
+// handler's groups, handler-level attrs under the groups active when they were
+// added, then context attrs
+func hookAttrs(r slog.Record, groups []string, handlerAttrs []groupedAttr, ctxAttrs []slog.Attr) []slog.Attr {
+	attrs := make([]slog.Attr, 0, r.NumAttrs()+len(handlerAttrs)+len(ctxAttrs))
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, nestAttr(groups, a))
+		return true
+	})
+	for _, ga := range handlerAttrs {
+		attrs = append(attrs, nestAttr(ga.groups, ga.attr))
+	}
+	return append(attrs, ctxAttrs...)
+}
@@ -0,0 +1,98 @@
+package httplog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"sync"
+)
+
+// clfTimeFormat is the timestamp layout of the Common Log Format
+const clfTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// CLFHandler is a slog.Handler writing access records in the Common Log
+// Format read by Apache/NGINX log analyzers:
+//
+//	192.0.2.1 - - [10/Oct/2025:13:55:36 +0000] "GET /index.html HTTP/1.1" 200 2326
+//
+// Fields come from the attrs HTTPMiddleware logs: remote_addr (its host),
+// user, method, path, proto, status and bytes; the message and other attrs
+// are dropped. Missing fields are written as "-". Use it as the access logger:
+//
+//	f, _ := os.OpenFile("access.log", os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+//	httplog.SetAccessLogger(slog.New(httplog.NewCLFHandler(f)))
+type CLFHandler struct {
+	mu    *sync.Mutex
+	w     io.Writer
+	attrs []slog.Attr
+}
+
+// NewCLFHandler creates a handler writing Common Log Format lines to w
+func NewCLFHandler(w io.Writer) *CLFHandler {
+	return &CLFHandler{mu: &sync.Mutex{}, w: w}
+}
+
+// Enabled accepts every level: an access log has one line per request
+func (h *CLFHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+// WithAttrs returns a handler that also reads fields from attrs
+func (h *CLFHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = append(h.attrs[:len(h.attrs):len(h.attrs)], attrs...)
+	return &h2
+}
+
+// WithGroup returns h: fields are matched by their own key in any group
+func (h *CLFHandler) WithGroup(string) slog.Handler { return h }
+
+func (h *CLFHandler) Handle(_ context.Context, r slog.Record) error {
+	fields := map[string]string{}
+	add := func(a slog.Attr) {
+		switch a.Key {
+		case "remote_addr", "user", "method", "path", "proto", "status", "bytes":
+			fields[a.Key] = a.Value.Resolve().String()
+		}
+	}
+	for _, a := range h.attrs {
+		add(a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		add(a)
+		return true
+	})
+
+	host := fields["remote_addr"]
+	if hostOnly, _, err := net.SplitHostPort(host); err == nil {
+		host = hostOnly
+	}
+	request := fields["method"] + " " + fields["path"]
+	if proto := fields["proto"]; proto != "" {
+		request += " " + proto
+	}
+	if fields["method"] == "" && fields["path"] == "" {
+		request = "-"
+	}
+	bytes := fields["bytes"]
+	if n, err := strconv.ParseInt(bytes, 10, 64); err == nil && n == 0 {
+		bytes = "-" // CLF writes "-" for an empty body
+	}
+
+	line := fmt.Sprintf("%s - %s [%s] %q %s %s\n",
+		orDash(host), orDash(fields["user"]), r.Time.Format(clfTimeFormat),
+		request, orDash(fields["status"]), orDash(bytes))
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, line)
+	return err
+}
+
+// orDash returns s, or "-" for a missing CLF field
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
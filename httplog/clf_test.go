@@ -0,0 +1,133 @@
+package httplog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clfTime is the record time of the golden lines
+var clfTime = time.Date(2025, 10, 10, 13, 55, 36, 0, time.FixedZone("", 0))
+
+// clfLine returns the line a CLFHandler writes for a record with attrs
+func clfLine(t *testing.T, h slog.Handler, buf *bytes.Buffer, attrs ...slog.Attr) string {
+	t.Helper()
+	buf.Reset()
+	r := slog.NewRecord(clfTime, slog.LevelInfo, "http request", 0)
+	r.AddAttrs(attrs...)
+	if err := h.Handle(context.Background(), r); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestCLFHandlerLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		attrs []slog.Attr
+		want  string
+	}{
+		{
+			name: "full",
+			attrs: []slog.Attr{
+				slog.String("method", "GET"), slog.String("path", "/index.html"),
+				slog.String("proto", "HTTP/1.1"), slog.Int("status", 200),
+				slog.Int("bytes", 2326), slog.String("remote_addr", "192.0.2.1:54321"),
+				slog.Duration("duration", time.Millisecond),
+			},
+			want: `192.0.2.1 - - [10/Oct/2025:13:55:36 +0000] "GET /index.html HTTP/1.1" 200 2326` + "\n",
+		},
+		{
+			name: "user and ipv6 host",
+			attrs: []slog.Attr{
+				slog.String("method", "POST"), slog.String("path", "/login"),
+				slog.String("proto", "HTTP/2.0"), slog.Int("status", 302),
+				slog.Int("bytes", 17), slog.String("remote_addr", "[2001:db8::1]:443"),
+				slog.String("user", "frank"),
+			},
+			want: `2001:db8::1 - frank [10/Oct/2025:13:55:36 +0000] "POST /login HTTP/2.0" 302 17` + "\n",
+		},
+		{
+			name: "remote_addr without port",
+			attrs: []slog.Attr{
+				slog.String("method", "GET"), slog.String("path", "/"),
+				slog.Int("status", 200), slog.Int("bytes", 5), slog.String("remote_addr", "192.0.2.7"),
+			},
+			want: `192.0.2.7 - - [10/Oct/2025:13:55:36 +0000] "GET /" 200 5` + "\n",
+		},
+		{
+			name: "zero bytes",
+			attrs: []slog.Attr{
+				slog.String("method", "HEAD"), slog.String("path", "/health"),
+				slog.String("proto", "HTTP/1.1"), slog.Int("status", 204),
+				slog.Int("bytes", 0), slog.String("remote_addr", "10.0.0.1:80"),
+			},
+			want: `10.0.0.1 - - [10/Oct/2025:13:55:36 +0000] "HEAD /health HTTP/1.1" 204 -` + "\n",
+		},
+		{
+			name:  "all missing",
+			attrs: nil,
+			want:  `- - - [10/Oct/2025:13:55:36 +0000] "-" - -` + "\n",
+		},
+		{
+			name: "quotes in the path",
+			attrs: []slog.Attr{
+				slog.String("method", "GET"), slog.String("path", `/say"hi"`),
+				slog.String("proto", "HTTP/1.1"), slog.Int("status", 404), slog.Int("bytes", 9),
+			},
+			want: `- - - [10/Oct/2025:13:55:36 +0000] "GET /say\"hi\" HTTP/1.1" 404 9` + "\n",
+		},
+	}
+	var buf bytes.Buffer
+	h := NewCLFHandler(&buf)
+	for _, tt := range tests {
+		if got := clfLine(t, h, &buf, tt.attrs...); got != tt.want {
+			t.Errorf("%s:\n got %q\nwant %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCLFHandlerTimeZone(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewCLFHandler(&buf)
+	r := slog.NewRecord(clfTime.In(time.FixedZone("", -7*3600)), slog.LevelInfo, "", 0)
+	if err := h.Handle(context.Background(), r); err != nil {
+		t.Fatal(err)
+	}
+	if want := "- - - [10/Oct/2025:06:55:36 -0700] \"-\" - -\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestCLFHandlerWithAttrsAndGroup(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewCLFHandler(&buf).WithAttrs([]slog.Attr{slog.String("user", "svc")}).WithGroup("req")
+	got := clfLine(t, h, &buf, slog.String("method", "GET"), slog.String("path", "/a"), slog.Int("status", 200))
+	if want := `- - svc [10/Oct/2025:13:55:36 +0000] "GET /a" 200 -` + "\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCLFHandlerAsAccessLogger(t *testing.T) {
+	var buf bytes.Buffer
+	SetAccessLogger(slog.New(NewCLFHandler(&buf)))
+	t.Cleanup(func() { SetAccessLogger(nil) })
+
+	req := httptest.NewRequest("GET", "/index.html", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("hello"))
+	})).ServeHTTP(httptest.NewRecorder(), req)
+
+	// The time comes from the clock, so only the fields around it are checked
+	line := buf.String()
+	if !strings.HasPrefix(line, "192.0.2.1 - - [") ||
+		!strings.HasSuffix(line, `] "GET /index.html HTTP/1.1" 200 5`+"\n") {
+		t.Errorf("line = %q", line)
+	}
+}
@@ -0,0 +1,65 @@
+// Package httplog provides HTTP and gRPC request logging helpers for glogi.
+// It lives in a separate package so the core glogi package stays free of net/http.
+package httplog
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+var (
+	headersMu     sync.RWMutex
+	loggedHeaders []string // Lowercased header/metadata keys to log
+)
+
+// SetLoggedHeaders sets the allowlist of request headers (HTTP) and metadata
+// keys (gRPC) attached to request logs as attributes. Matching is
+// case-insensitive; the attribute key is the lowercased header name.
+// Nothing is logged by default, so sensitive headers are never captured
+// unless explicitly listed. Call with no keys to clear.
+//
+//	httplog.SetLoggedHeaders("X-Request-Id", "User-Agent")
+func SetLoggedHeaders(keys ...string) {
+	lowered := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if k = strings.ToLower(strings.TrimSpace(k)); k != "" {
+			lowered = append(lowered, k)
+		}
+	}
+	headersMu.Lock()
+	loggedHeaders = lowered
+	headersMu.Unlock()
+}
+
+// HeaderAttrs returns attrs for the allowlisted headers present in h.
+// Multiple values are joined with ", ".
+func HeaderAttrs(h http.Header) []slog.Attr {
+	headersMu.RLock()
+	defer headersMu.RUnlock()
+
+	var attrs []slog.Attr
+	for _, key := range loggedHeaders {
+		if vals := h.Values(key); len(vals) > 0 {
+			attrs = append(attrs, slog.String(key, strings.Join(vals, ", ")))
+		}
+	}
+	return attrs
+}
+
+// MetadataAttrs returns attrs for the allowlisted keys present in gRPC metadata.
+// A metadata.MD can be passed directly (its underlying type is map[string][]string,
+// with lowercased keys), so this package does not depend on grpc.
+func MetadataAttrs(md map[string][]string) []slog.Attr {
+	headersMu.RLock()
+	defer headersMu.RUnlock()
+
+	var attrs []slog.Attr
+	for _, key := range loggedHeaders {
+		if vals := md[key]; len(vals) > 0 {
+			attrs = append(attrs, slog.String(key, strings.Join(vals, ", ")))
+		}
+	}
+	return attrs
+}
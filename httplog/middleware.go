@@ -0,0 +1,114 @@
+package httplog
+
+import (
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	log "github.com/neoff/glogi"
+)
+
+// responseRecorder captures the status code and body size of a response
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (rr *responseRecorder) WriteHeader(code int) {
+	if rr.status == 0 {
+		rr.status = code
+	}
+	rr.ResponseWriter.WriteHeader(code)
+}
+
+func (rr *responseRecorder) Write(p []byte) (int, error) {
+	if rr.status == 0 {
+		rr.status = http.StatusOK
+	}
+	n, err := rr.ResponseWriter.Write(p)
+	rr.bytes += int64(n)
+	return n, err
+}
+
+// Flush passes flushes through for streaming handlers
+func (rr *responseRecorder) Flush() {
+	if f, ok := rr.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the wrapped writer, for http.ResponseController
+func (rr *responseRecorder) Unwrap() http.ResponseWriter {
+	return rr.ResponseWriter
+}
+
+// accessLogger receives HTTPMiddleware records instead of the global logger
+var accessLogger atomic.Pointer[slog.Logger]
+
+// SetAccessLogger makes HTTPMiddleware log to l instead of the global glogi
+// logger, e.g. a separate access log in the Common Log Format (see
+// NewCLFHandler). nil restores the global logger.
+func SetAccessLogger(l *slog.Logger) { accessLogger.Store(l) }
+
+// HTTPMiddleware logs one access record per request after next has served
+// it, with the request context so context attrs (trace IDs, worker labels)
+// are included:
+//
+//	... http request method=GET path=/users proto=HTTP/1.1 status=200 duration=1.234ms bytes=512 remote_addr=192.0.2.1:51234
+//
+// Responses with status 500 and above are logged at ERROR, 400 and above at
+// WARN, others at INFO. Headers listed with SetLoggedHeaders are added.
+// Records go to the global logger, or to the one set with SetAccessLogger.
+//
+//	http.ListenAndServe(":8080", httplog.HTTPMiddleware(mux))
+func HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &responseRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK // Nothing written: net/http sends 200
+		}
+		args := []any{
+			"method", r.Method,
+			"path", r.URL.Path,
+			"proto", r.Proto,
+			"status", status,
+			"duration", time.Since(start),
+			"bytes", rec.bytes,
+			"remote_addr", r.RemoteAddr,
+		}
+		for _, a := range HeaderAttrs(r.Header) {
+			args = append(args, a)
+		}
+
+		if l := accessLogger.Load(); l != nil {
+			l.Log(r.Context(), accessLevel(status), "http request", args...)
+			return
+		}
+		switch {
+		case status >= 500:
+			log.ErrorContext(r.Context(), "http request", args...)
+		case status >= 400:
+			log.WarnContext(r.Context(), "http request", args...)
+		default:
+			log.InfoContext(r.Context(), "http request", args...)
+		}
+	})
+}
+
+// accessLevel returns the level of an access record for a response status
+func accessLevel(status int) slog.Level {
+	switch {
+	case status >= 500:
+		return slog.LevelError
+	case status >= 400:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
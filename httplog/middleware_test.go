@@ -0,0 +1,177 @@
+package httplog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	log "github.com/neoff/glogi"
+)
+
+// captureLog sets the global glogi logger up again with text output written
+// to the returned buffer, and closes it after the test
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	_ = log.Close()
+	t.Setenv("LOG_FORMAT", "text")
+	t.Setenv("LOG_LEVEL", "INFO")
+	t.Setenv("LOG_FILE", "")
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	log.Init()
+	t.Cleanup(func() { _ = log.Close() })
+	return &buf
+}
+
+// recordHandler keeps the records and contexts it handles
+type recordHandler struct {
+	mu      sync.Mutex
+	records []slog.Record
+	ctxs    []context.Context
+}
+
+func (h *recordHandler) Enabled(context.Context, slog.Level) bool { return true }
+func (h *recordHandler) WithAttrs([]slog.Attr) slog.Handler       { return h }
+func (h *recordHandler) WithGroup(string) slog.Handler            { return h }
+
+func (h *recordHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, r)
+	h.ctxs = append(h.ctxs, ctx)
+	return nil
+}
+
+// attrs returns the attrs of a record by key
+func attrs(r slog.Record) map[string]slog.Value {
+	m := map[string]slog.Value{}
+	r.Attrs(func(a slog.Attr) bool {
+		m[a.Key] = a.Value
+		return true
+	})
+	return m
+}
+
+// serve runs a request through HTTPMiddleware(next) with an access logger
+// recording its records
+func serve(t *testing.T, next http.HandlerFunc, req *http.Request) (*httptest.ResponseRecorder, *recordHandler) {
+	t.Helper()
+	h := &recordHandler{}
+	SetAccessLogger(slog.New(h))
+	t.Cleanup(func() { SetAccessLogger(nil) })
+	w := httptest.NewRecorder()
+	HTTPMiddleware(next).ServeHTTP(w, req)
+	if len(h.records) != 1 {
+		t.Fatalf("got %d access records, want 1", len(h.records))
+	}
+	return w, h
+}
+
+func TestMiddlewareStatusLevel(t *testing.T) {
+	tests := []struct {
+		status int
+		level  slog.Level
+	}{
+		{http.StatusOK, slog.LevelInfo},
+		{http.StatusFound, slog.LevelInfo},
+		{http.StatusBadRequest, slog.LevelWarn},
+		{http.StatusNotFound, slog.LevelWarn},
+		{http.StatusInternalServerError, slog.LevelError},
+		{http.StatusServiceUnavailable, slog.LevelError},
+	}
+	for _, tt := range tests {
+		_, h := serve(t, func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(tt.status) },
+			httptest.NewRequest("GET", "/users", nil))
+		r := h.records[0]
+		if r.Level != tt.level {
+			t.Errorf("status %d logged at %s, want %s", tt.status, r.Level, tt.level)
+		}
+		if got := attrs(r)["status"].Int64(); got != int64(tt.status) {
+			t.Errorf("status attr = %d, want %d", got, tt.status)
+		}
+	}
+}
+
+func TestMiddlewareGlobalLoggerLevel(t *testing.T) {
+	buf := captureLog(t)
+	for _, status := range []int{200, 404, 500} {
+		HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(status) })).
+			ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("output = %q", buf.String())
+	}
+	for i, want := range []string{"] INFO ", "] WARN ", "] ERROR "} {
+		if !strings.Contains(lines[i], want) {
+			t.Errorf("line %d = %q, want level %q", i, lines[i], want)
+		}
+	}
+}
+
+func TestMiddlewareImplicitOK(t *testing.T) {
+	w, h := serve(t, func(http.ResponseWriter, *http.Request) {}, httptest.NewRequest("GET", "/", nil))
+	a := attrs(h.records[0])
+	if a["status"].Int64() != http.StatusOK || h.records[0].Level != slog.LevelInfo {
+		t.Errorf("status = %v at %s, want 200 at INFO", a["status"], h.records[0].Level)
+	}
+	if a["bytes"].Int64() != 0 || w.Code != http.StatusOK {
+		t.Errorf("bytes = %v, response code %d", a["bytes"], w.Code)
+	}
+}
+
+func TestMiddlewareCountsBytes(t *testing.T) {
+	w, h := serve(t, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("hello, "))
+		_, _ = w.Write([]byte("world"))
+	}, httptest.NewRequest("POST", "/greet", nil))
+	a := attrs(h.records[0])
+	if a["bytes"].Int64() != 12 || w.Body.String() != "hello, world" {
+		t.Errorf("bytes = %v, body %q", a["bytes"], w.Body.String())
+	}
+	if a["status"].Int64() != http.StatusOK {
+		t.Errorf("status = %v, want 200 from the first Write", a["status"])
+	}
+	if a["method"].String() != "POST" || a["path"].String() != "/greet" {
+		t.Errorf("method = %v, path = %v", a["method"], a["path"])
+	}
+}
+
+func TestMiddlewareFlushPassesThrough(t *testing.T) {
+	w, _ := serve(t, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("chunk"))
+		f, ok := w.(http.Flusher)
+		if !ok {
+			t.Fatal("wrapped writer isn't an http.Flusher")
+		}
+		f.Flush()
+	}, httptest.NewRequest("GET", "/stream", nil))
+	if !w.Flushed {
+		t.Error("Flush didn't reach the underlying writer")
+	}
+}
+
+func TestMiddlewarePassesRequestContext(t *testing.T) {
+	type traceKey struct{}
+	req := httptest.NewRequest("GET", "/", nil)
+	req = req.WithContext(context.WithValue(req.Context(), traceKey{}, "trace-1"))
+	_, h := serve(t, func(http.ResponseWriter, *http.Request) {}, req)
+	if got := h.ctxs[0].Value(traceKey{}); got != "trace-1" {
+		t.Errorf("access logger context value = %v, want trace-1", got)
+	}
+
+	// Through the global logger, context attrs end up in the line
+	buf := captureLog(t)
+	SetAccessLogger(nil)
+	req = httptest.NewRequest("GET", "/", nil)
+	req = req.WithContext(log.WithWorkerLabel(req.Context(), "w7"))
+	HTTPMiddleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})).ServeHTTP(httptest.NewRecorder(), req)
+	if !strings.Contains(buf.String(), "worker=w7") {
+		t.Errorf("output = %q", buf.String())
+	}
+}
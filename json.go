@@ -0,0 +1,648 @@
+package glogi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ecsVersion is the Elastic Common Schema version emitted in ECS mode
+const ecsVersion = "8.11.0"
+
+// jsonMarshal encodes values for the structured (JSON-based) handlers
+var jsonMarshal = json.Marshal
+
+// SetJSONMarshaler replaces the encoder used by the structured handlers,
+// e.g. to plug in jsoniter or sonic for high-volume JSON logging. Default is
+// encoding/json.Marshal; nil restores it.
+//
+// The function is called concurrently from every goroutine that logs, so it
+// must be safe for concurrent use. It should produce standard JSON and handle
+// map[string]any, slices and the values passed as attributes.
+func SetJSONMarshaler(fn func(any) ([]byte, error)) {
+	if fn == nil {
+		fn = json.Marshal
+	}
+	jsonMarshal = fn
+}
+
+// GroupStyle controls how groups are rendered by the JSON handlers
+type GroupStyle string
+
+// Group styles
+const (
+	GroupStyleNested GroupStyle = "nested" // {"http":{"req":{"id":1}}}
+	GroupStyleFlat   GroupStyle = "flat"   // {"http.req.id":1}
+)
+
+// groupStyle is the active JSON group style (LOG_GROUP_STYLE)
+var groupStyle = GroupStyleNested
+
+// SetGroupStyle sets how the JSON handlers render groups: nested objects
+// (default) or flat dotted keys for backends that can't query nested objects.
+// The colored text handler always uses dotted keys.
+func SetGroupStyle(style GroupStyle) {
+	if style == GroupStyleFlat {
+		groupStyle = GroupStyleFlat
+	} else {
+		groupStyle = GroupStyleNested
+	}
+}
+
+// Keys of the built-in fields in JSON and logfmt output
+var (
+	timeKey    = "time"
+	levelKey   = "level"
+	sourceKey  = "source"
+	messageKey = "msg"
+)
+
+// SetTimeKey sets the key of the record time in JSON and logfmt output
+// ("time" by default; "" restores it). ECS output keeps its schema names.
+func SetTimeKey(key string) { timeKey = keyOr(key, "time") }
+
+// SetLevelKey sets the key of the level in JSON and logfmt output ("level" by default)
+func SetLevelKey(key string) { levelKey = keyOr(key, "level") }
+
+// SetSourceKey sets the key of the source location in JSON and logfmt output ("source" by default)
+func SetSourceKey(key string) { sourceKey = keyOr(key, "source") }
+
+// SetMessageKey sets the key of the message in JSON and logfmt output
+// ("msg" by default), e.g. "message" to match an existing schema
+func SetMessageKey(key string) { messageKey = keyOr(key, "msg") }
+
+// keyOr returns key, or def when key is empty
+func keyOr(key, def string) string {
+	if key == "" {
+		return def
+	}
+	return key
+}
+
+// jsonGroup is a level of attribute nesting opened by WithGroup.
+// The root group has an empty name.
+type jsonGroup struct {
+	name  string
+	attrs []slog.Attr
+}
+
+// JSONHandler implements slog.Handler and writes one JSON object per record:
+//
+//	{"time":"...","level":"INFO","source":"main.go:18","msg":"server started","port":8080}
+//
+// Attributes are top-level keys; groups become nested objects.
+type JSONHandler struct {
+	level  *slog.LevelVar
+	writer *outputRef
+	groups []jsonGroup // groups[0] is the root
+	ecs    bool        // Use Elastic Common Schema field names
+	gcp    bool        // Use Google Cloud Logging field names
+}
+
+// NewJSONHandler creates a new JSON lines handler
+func NewJSONHandler(w io.Writer, level *slog.LevelVar) slog.Handler {
+	initConfig() // Read config from env on first handler creation
+	return &JSONHandler{
+		level:  level,
+		writer: newOutputRef(w),
+		groups: []jsonGroup{{}},
+	}
+}
+
+// NewECSHandler creates a JSON lines handler using Elastic Common Schema fields:
+//
+//	{"@timestamp":"...","log":{"level":"info","origin":{"file":{"name":"main.go","line":18}}},
+//	 "message":"server started","ecs":{"version":"8.11.0"},"port":8080}
+//
+// A "stack" attribute (from Recover or the *Stack helpers) is emitted as error.stack_trace.
+func NewECSHandler(w io.Writer, level *slog.LevelVar) slog.Handler {
+	initConfig() // Read config from env on first handler creation
+	return &JSONHandler{
+		level:  level,
+		writer: newOutputRef(w),
+		groups: []jsonGroup{{}},
+		ecs:    true,
+	}
+}
+
+// NewGCPHandler creates a JSON lines handler for Google Cloud Logging (GKE,
+// Cloud Run), which parses the severity, message and source location natively:
+//
+//	{"time":"...","severity":"WARNING","logging.googleapis.com/sourceLocation":{"file":"main.go","line":"18"},
+//	 "message":"disk almost full","free_mb":120}
+//
+// TRACE and DEBUG map to DEBUG, WARN to WARNING, FATAL and PANIC to CRITICAL.
+// A "stack" attribute is emitted as stack_trace for Error Reporting.
+func NewGCPHandler(w io.Writer, level *slog.LevelVar) slog.Handler {
+	initConfig() // Read config from env on first handler creation
+	return &JSONHandler{
+		level:  level,
+		writer: newOutputRef(w),
+		groups: []jsonGroup{{}},
+		gcp:    true,
+	}
+}
+
+func (h *JSONHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	if (silent && l < LevelFatal) || skipCancelled(ctx, l) {
+		return false
+	}
+	if forced, ok := forcedLevel(ctx); ok {
+		return l >= forced
+	}
+	if sub, ok := subsystemLevel(h.firstGroup(), h.handlerAttrs); ok {
+		return l >= sub
+	}
+	return l >= h.level.Level()
+}
+
+func (h *JSONHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !admitRecord(ctx, &r, h.handlerAttrs) {
+		return nil
+	}
+
+	source, overridden := takeSourceOverride(&r)
+	var buf bytes.Buffer
+	if h.ecs {
+		h.writeECSHeader(&buf, r, source, overridden)
+	} else if h.gcp {
+		h.writeGCPHeader(&buf, r, source, overridden)
+	} else {
+		buf.WriteByte('{')
+		writeJSONValue(&buf, timeKey)
+		buf.WriteByte(':')
+		writeJSONValue(&buf, recordTime(r.Time).Format(time.RFC3339Nano))
+		buf.WriteByte(',')
+		writeJSONValue(&buf, levelKey)
+		buf.WriteByte(':')
+		writeJSONValue(&buf, levelName(r.Level))
+		if source != "" {
+			buf.WriteByte(',')
+			writeJSONValue(&buf, sourceKey)
+			buf.WriteByte(':')
+			writeJSONValue(&buf, source)
+		}
+		buf.WriteByte(',')
+		writeJSONValue(&buf, messageKey)
+		buf.WriteByte(':')
+		writeJSONValue(&buf, r.Message)
+	}
+
+	// Context attrs (worker label, deadline) are top-level
+	ctxAttrs := contextAttrs(ctx)
+	for _, a := range ctxAttrs {
+		writeJSONAttr(&buf, "", a)
+	}
+
+	// Record attrs belong to the innermost group
+	var recAttrs []slog.Attr
+	var stack *slog.Attr
+	r.Attrs(func(a slog.Attr) bool {
+		if (h.ecs || h.gcp) && a.Key == "stack" {
+			stack = &a
+			return true
+		}
+		recAttrs = append(recAttrs, a)
+		return true
+	})
+	var shadowed map[string]bool
+	if dedupeKeys && len(ctxAttrs) > 0 {
+		// Context attrs win over top-level attrs with the same key
+		shadowed = make(map[string]bool, len(ctxAttrs))
+		for _, a := range ctxAttrs {
+			shadowed[a.Key] = true
+		}
+	}
+	h.writeGroups(&buf, 0, "", recAttrs, shadowed)
+	if hooksDue(ctx) {
+		runHooks(r, h.hookAttrs(r, ctxAttrs))
+	}
+
+	if stack != nil && h.gcp {
+		buf.WriteString(`,"stack_trace":`)
+		writeJSONValue(&buf, stack.Value.Resolve().String())
+	} else if stack != nil {
+		buf.WriteString(`,"error":{"stack_trace":`)
+		writeJSONValue(&buf, stack.Value.Resolve().String())
+		buf.WriteByte('}')
+	}
+
+	buf.WriteByte('}')
+	buf.WriteString(lineTerminator)
+
+	var err error
+	if box := routeRecord(ctx, r); box != nil {
+		_, err = writeRouted(box, r.Level, buf.Bytes())
+	} else {
+		_, err = h.writer.writeLevel(r.Level, buf.Bytes())
+	}
+	if terr := h.writer.writeTees(r.Level, func(bool) []byte { return buf.Bytes() }); err == nil {
+		err = terr
+	}
+	return err
+}
+
+// handlerAttrs returns the attrs added with WithAttrs, without group paths
+func (h *JSONHandler) handlerAttrs() []slog.Attr {
+	var attrs []slog.Attr
+	for _, g := range h.groups {
+		attrs = append(attrs, g.attrs...)
+	}
+	return attrs
+}
+
+// hookAttrs collects a record's attrs for hooks, nesting each group's attrs
+// (and the record attrs, in the innermost group) in a group attr
+func (h *JSONHandler) hookAttrs(r slog.Record, ctxAttrs []slog.Attr) []slog.Attr {
+	var attrs []slog.Attr
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	for i := len(h.groups) - 1; i >= 0; i-- {
+		g := h.groups[i]
+		attrs = append(append([]slog.Attr(nil), g.attrs...), attrs...)
+		if i > 0 {
+			attrs = []slog.Attr{{Key: g.name, Value: slog.GroupValue(attrs...)}}
+		}
+	}
+	return append(attrs, ctxAttrs...)
+}
+
+// writeECSHeader writes the opening brace and the ECS base fields. An
+// overridden source (a _source attr) becomes the origin file name, without a line.
+func (h *JSONHandler) writeECSHeader(buf *bytes.Buffer, r slog.Record, source string, overridden bool) {
+	buf.WriteString(`{"@timestamp":`)
+	writeJSONValue(buf, recordTime(r.Time).Format(time.RFC3339Nano))
+	buf.WriteString(`,"log":{"level":`)
+	writeJSONValue(buf, strings.ToLower(levelName(r.Level)))
+	if overridden {
+		buf.WriteString(`,"origin":{"file":{"name":`)
+		writeJSONValue(buf, source)
+		buf.WriteString(`}}`)
+	} else if r.Level >= sourceMinLevel {
+		if file, line, fn := sourceFileLine(r.PC); file != "" {
+			buf.WriteString(`,"origin":{"file":{"name":`)
+			writeJSONValue(buf, file)
+			fmt.Fprintf(buf, `,"line":%d}`, line)
+			if showFunction && fn != "" {
+				buf.WriteString(`,"function":`)
+				writeJSONValue(buf, fn)
+			}
+			buf.WriteString(`}`)
+		}
+	}
+	buf.WriteString(`},"message":`)
+	writeJSONValue(buf, r.Message)
+	buf.WriteString(`,"ecs":{"version":"` + ecsVersion + `"}`)
+}
+
+// gcpSeverity maps a level to a Cloud Logging severity name
+func gcpSeverity(l slog.Level) string {
+	switch l = standardLevel(l); {
+	case l >= LevelFatal:
+		return "CRITICAL"
+	case l >= LevelError:
+		return "ERROR"
+	case l >= LevelWarn:
+		return "WARNING"
+	case l >= LevelInfo:
+		return "INFO"
+	default:
+		return "DEBUG"
+	}
+}
+
+// writeGCPHeader writes the opening brace and the Cloud Logging base fields.
+// An overridden source (a _source attr) becomes the location's file, without a line.
+func (h *JSONHandler) writeGCPHeader(buf *bytes.Buffer, r slog.Record, source string, overridden bool) {
+	buf.WriteString(`{"time":`)
+	writeJSONValue(buf, recordTime(r.Time).Format(time.RFC3339Nano))
+	buf.WriteString(`,"severity":`)
+	writeJSONValue(buf, gcpSeverity(r.Level))
+	if overridden {
+		buf.WriteString(`,"logging.googleapis.com/sourceLocation":{"file":`)
+		writeJSONValue(buf, source)
+		buf.WriteByte('}')
+	} else if r.Level >= sourceMinLevel {
+		if file, line, fn := sourceFileLine(r.PC); file != "" {
+			buf.WriteString(`,"logging.googleapis.com/sourceLocation":{"file":`)
+			writeJSONValue(buf, file)
+			fmt.Fprintf(buf, `,"line":"%d"`, line) // int64 fields are strings in Cloud Logging JSON
+			if showFunction && fn != "" {
+				buf.WriteString(`,"function":`)
+				writeJSONValue(buf, fn)
+			}
+			buf.WriteByte('}')
+		}
+	}
+	buf.WriteString(`,"message":`)
+	writeJSONValue(buf, r.Message)
+}
+
+// writeGroups writes the attrs of groups[i:] with deeper groups nested as objects
+// (or as dotted key prefixes in flat style). Groups without any attrs
+// (including descendants) are omitted. With SetDedupeKeys, repeated keys in an
+// object keep their last value and keys in shadowed are left out.
+func (h *JSONHandler) writeGroups(buf *bytes.Buffer, i int, prefix string, recAttrs []slog.Attr, shadowed map[string]bool) {
+	own := h.groups[i].attrs
+	innermost := i == len(h.groups)-1
+	var rec []slog.Attr
+	if innermost {
+		rec = recAttrs
+	}
+	if dedupeKeys {
+		// Record attrs win over the group's own attrs with the same key
+		ownShadowed := shadowed
+		if len(rec) > 0 {
+			ownShadowed = make(map[string]bool, len(shadowed)+len(rec))
+			for k := range shadowed {
+				ownShadowed[k] = true
+			}
+			for _, a := range rec {
+				if a.Key != "" {
+					ownShadowed[a.Key] = true
+				}
+			}
+		}
+		own = dedupeAttrs(own, ownShadowed)
+		rec = dedupeAttrs(rec, shadowed)
+	}
+	first, second := own, rec
+	if !handlerAttrsFirst {
+		first, second = rec, own
+	}
+	for _, a := range first {
+		writeJSONAttr(buf, prefix, a)
+	}
+	for _, a := range second {
+		writeJSONAttr(buf, prefix, a)
+	}
+	if innermost {
+		return
+	}
+	if !h.groupHasAttrs(i+1, recAttrs) {
+		return
+	}
+	name := h.groups[i+1].name
+	if groupStyle == GroupStyleFlat {
+		h.writeGroups(buf, i+1, prefix+name+".", recAttrs, nil)
+		return
+	}
+	buf.WriteByte(',')
+	writeJSONValue(buf, name)
+	buf.WriteString(`:{`)
+	inner := bytes.Buffer{}
+	h.writeGroups(&inner, i+1, "", recAttrs, nil)
+	buf.Write(bytes.TrimPrefix(inner.Bytes(), []byte(",")))
+	buf.WriteByte('}')
+}
+
+// dedupeAttrs keeps only the last attr for each key, dropping keys in
+// shadowed. Inline groups (empty key) are always kept.
+func dedupeAttrs(attrs []slog.Attr, shadowed map[string]bool) []slog.Attr {
+	last := make(map[string]int, len(attrs))
+	for i, a := range attrs {
+		last[a.Key] = i
+	}
+	out := make([]slog.Attr, 0, len(attrs))
+	for i, a := range attrs {
+		if a.Key == "" || (last[a.Key] == i && !shadowed[a.Key]) {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// groupHasAttrs reports whether groups[i:] or the record carry any attrs
+func (h *JSONHandler) groupHasAttrs(i int, recAttrs []slog.Attr) bool {
+	if len(recAttrs) > 0 {
+		return true
+	}
+	for _, g := range h.groups[i:] {
+		if len(g.attrs) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *JSONHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	groups := make([]jsonGroup, len(h.groups))
+	copy(groups, h.groups)
+	last := &groups[len(groups)-1]
+	last.attrs = append(last.attrs[:len(last.attrs):len(last.attrs)], attrs...)
+	return &JSONHandler{level: h.level, writer: h.writer, groups: groups, ecs: h.ecs, gcp: h.gcp}
+}
+
+func (h *JSONHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	groups := make([]jsonGroup, len(h.groups), len(h.groups)+1)
+	copy(groups, h.groups)
+	return &JSONHandler{level: h.level, writer: h.writer, groups: append(groups, jsonGroup{name: name}), ecs: h.ecs, gcp: h.gcp}
+}
+
+// firstGroup returns the outermost group name ("" without groups)
+func (h *JSONHandler) firstGroup() string {
+	if len(h.groups) < 2 {
+		return ""
+	}
+	return h.groups[1].name
+}
+
+// withLevel returns a copy of the handler with its own minimum level
+func (h *JSONHandler) withLevel(lv *slog.LevelVar) slog.Handler {
+	return &JSONHandler{level: lv, writer: h.writer, groups: h.groups, ecs: h.ecs, gcp: h.gcp}
+}
+
+// withWriter returns a copy of the handler writing to w
+func (h *JSONHandler) withWriter(w io.Writer) slog.Handler {
+	out := &outputRef{}
+	out.storeAs(w, h.writer.IsTerminal())
+	out.tees.Store(h.writer.tees.Load())
+	return &JSONHandler{level: h.level, writer: out, groups: h.groups, ecs: h.ecs, gcp: h.gcp}
+}
+
+// output returns the handler's writer
+func (h *JSONHandler) output() io.Writer { return h.writer }
+
+// setOutput swaps the writer for this handler and all handlers sharing it
+func (h *JSONHandler) setOutput(w io.Writer) { h.writer.Store(w) }
+
+// setErrorOutput sets the WARN+ destination for this handler and all handlers sharing its writer
+func (h *JSONHandler) setErrorOutput(w io.Writer) { h.writer.storeError(w) }
+
+// addOutput adds an extra destination for this handler and all handlers sharing its writer
+func (h *JSONHandler) addOutput(w io.Writer, _ bool) { h.writer.addTee(w, false) }
+
+// maxAttrDepth limits group nesting when rendering attrs, guarding against
+// LogValuers that resolve to groups containing themselves. Deeper groups are
+// rendered as maxDepthValue.
+const (
+	maxAttrDepth  = 32
+	maxDepthValue = "!MAXDEPTH"
+)
+
+// writeJSONAttr writes `,"key":value`, expanding group values into objects
+// (or into prefixed dotted keys in flat style)
+func writeJSONAttr(buf *bytes.Buffer, prefix string, a slog.Attr) {
+	writeJSONAttrDepth(buf, prefix, a, 0)
+}
+
+func writeJSONAttrDepth(buf *bytes.Buffer, prefix string, a slog.Attr, depth int) {
+	a = redactAttr(a)
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup && depth >= maxAttrDepth {
+		v = slog.StringValue(maxDepthValue)
+	}
+	if v.Kind() == slog.KindGroup {
+		group := v.Group()
+		if len(group) == 0 {
+			return // empty groups are dropped
+		}
+		if a.Key == "" {
+			// Inline group: attrs go to the current level
+			for _, ga := range group {
+				writeJSONAttrDepth(buf, prefix, ga, depth+1)
+			}
+			return
+		}
+		if groupStyle == GroupStyleFlat {
+			for _, ga := range group {
+				writeJSONAttrDepth(buf, prefix+a.Key+".", ga, depth+1)
+			}
+			return
+		}
+		buf.WriteByte(',')
+		writeJSONValue(buf, prefix+a.Key)
+		buf.WriteString(`:{`)
+		inner := bytes.Buffer{}
+		for _, ga := range group {
+			writeJSONAttrDepth(&inner, "", ga, depth+1)
+		}
+		buf.Write(bytes.TrimPrefix(inner.Bytes(), []byte(",")))
+		buf.WriteByte('}')
+		return
+	}
+	if a.Key == "" {
+		return
+	}
+	buf.WriteByte(',')
+	writeJSONValue(buf, prefix+a.Key)
+	buf.WriteByte(':')
+	writeJSONValue(buf, jsonValue(v))
+}
+
+// writeJSONValue encodes v with the configured marshaler, falling back to a
+// quoted %+v string when it cannot be encoded
+func writeJSONValue(buf *bytes.Buffer, v any) {
+	b, err := jsonMarshal(v)
+	if err != nil {
+		b, _ = json.Marshal(fmt.Sprintf("%+v", v))
+	}
+	buf.Write(b)
+}
+
+// jsonValue converts a resolved non-group slog value to a JSON-friendly value:
+// numbers and booleans stay JSON numbers and booleans, durations and times
+// become strings, and other values are left to the JSON marshaler (falling
+// back to their %+v string if they can't be marshaled)
+func jsonValue(v slog.Value) any {
+	switch v.Kind() {
+	case slog.KindString:
+		return truncateValue(v.String())
+	case slog.KindInt64:
+		return v.Int64()
+	case slog.KindUint64:
+		return v.Uint64()
+	case slog.KindFloat64:
+		f := v.Float64()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return strconv.FormatFloat(f, 'g', -1, 64) // Not representable in JSON
+		}
+		return f
+	case slog.KindBool:
+		return v.Bool()
+	case slog.KindDuration:
+		return v.Duration().String()
+	case slog.KindTime:
+		return v.Time().Format(time.RFC3339Nano)
+	case slog.KindAny:
+		if isNilValue(v.Any()) {
+			return nil // A typed nil (e.g. a nil *MyError) would panic in Error
+		}
+		if err, ok := v.Any().(error); ok {
+			return truncateValue(err.Error())
+		}
+		return v.Any()
+	default:
+		return v.Any()
+	}
+}
+
+// groupedAttr is a handler-level attr stored with the group path that was
+// active when it was added via WithAttrs (groups opened later don't apply)
+type groupedAttr struct {
+	groups []string
+	attr   slog.Attr
+}
+
+// appendGroupedAttrs returns a copy of existing with attrs added under groups
+func appendGroupedAttrs(existing []groupedAttr, groups []string, attrs []slog.Attr) []groupedAttr {
+	out := make([]groupedAttr, 0, len(existing)+len(attrs))
+	out = append(out, existing...)
+	for _, a := range attrs {
+		out = append(out, groupedAttr{groups: groups, attr: a})
+	}
+	return out
+}
+
+// groupPrefix joins groups into a dotted key prefix ("a.b.")
+func groupPrefix(groups []string) string {
+	prefix := ""
+	for _, g := range groups {
+		prefix += g + "."
+	}
+	return prefix
+}
+
+// addJSONAttr stores an attribute into m under a dotted key, expanding groups
+func addJSONAttr(m map[string]any, prefix string, a slog.Attr) {
+	addJSONAttrDepth(m, prefix, a, 0)
+}
+
+func addJSONAttrDepth(m map[string]any, prefix string, a slog.Attr, depth int) {
+	a = redactAttr(a)
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup && depth >= maxAttrDepth {
+		v = slog.StringValue(maxDepthValue)
+	}
+	if v.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range v.Group() {
+			addJSONAttrDepth(m, prefix, ga, depth+1)
+		}
+		return
+	}
+	if a.Key == "" {
+		return
+	}
+	m[prefix+a.Key] = jsonValue(v)
+}
+
+// Ensure JSONHandler implements slog.Handler
+var _ slog.Handler = (*JSONHandler)(nil)
@@ -0,0 +1,117 @@
+package glogi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONTypedValues(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(NewJSONHandler(&buf, &slog.LevelVar{}))
+	l.Info("typed",
+		"count", 5,
+		"big", uint64(math.MaxUint64),
+		"ratio", 0.25,
+		"ok", true,
+		"nan", math.NaN(),
+		"took", 1500*time.Millisecond,
+		"point", struct{ X, Y int }{1, 2},
+	)
+
+	out := buf.String()
+	for _, want := range []string{
+		`"count":5`, `"big":18446744073709551615`, `"ratio":0.25`, `"ok":true`,
+		`"nan":"NaN"`, `"took":"1.5s"`, `"point":{"X":1,"Y":2}`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %s: %s", want, out)
+		}
+	}
+	if strings.Contains(out, `"count":"5"`) || strings.Contains(out, `"ok":"true"`) {
+		t.Errorf("numbers or booleans were quoted: %s", out)
+	}
+
+	var m map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out, err)
+	}
+	if _, ok := m["count"].(float64); !ok {
+		t.Errorf("count decoded as %T", m["count"])
+	}
+	if _, ok := m["ok"].(bool); !ok {
+		t.Errorf("ok decoded as %T", m["ok"])
+	}
+}
+
+// nilErr is an error type whose nil pointer panics in Error
+type nilErr struct{ msg string }
+
+func (e *nilErr) Error() string { return e.msg }
+
+func TestJSONNilAndEmptyValues(t *testing.T) {
+	handlers := map[string]func(w io.Writer, lv *slog.LevelVar) slog.Handler{
+		"json": func(w io.Writer, lv *slog.LevelVar) slog.Handler { return NewJSONHandler(w, lv) },
+		"ecs":  func(w io.Writer, lv *slog.LevelVar) slog.Handler { return NewECSHandler(w, lv) },
+		"gcp":  func(w io.Writer, lv *slog.LevelVar) slog.Handler { return NewGCPHandler(w, lv) },
+	}
+	for name, newHandler := range handlers {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			var nilPtr *int
+			var typedNilErr *nilErr
+			slog.New(newHandler(&buf, &slog.LevelVar{})).Info("x",
+				"a", nil, "b", nilPtr, "c", "", "e", typedNilErr, "g", slog.GroupValue(slog.Any("e", typedNilErr)))
+			for _, want := range []string{`"a":null`, `"b":null`, `"c":""`, `"e":null`} {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("output lacks %s: %s", want, buf.String())
+				}
+			}
+			var m map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+				t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+			}
+		})
+	}
+}
+
+func TestGroupStyle(t *testing.T) {
+	t.Cleanup(func() { SetGroupStyle(GroupStyleNested) })
+	tests := []struct {
+		style GroupStyle
+		want  []string
+	}{
+		{GroupStyleNested, []string{`"http":{"svc":"api","req":{"id":7,"peer":{"ip":"10.0.0.1"}}}`}},
+		{GroupStyleFlat, []string{`"http.svc":"api"`, `"http.req.id":7`, `"http.req.peer.ip":"10.0.0.1"`}},
+		{"unknown", []string{`"http":{"svc":"api","req":{"id":7,"peer":{"ip":"10.0.0.1"}}}`}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.style), func(t *testing.T) {
+			SetGroupStyle(tt.style)
+			var jsonBuf, textBuf bytes.Buffer
+			for _, h := range []slog.Handler{NewJSONHandler(&jsonBuf, &slog.LevelVar{}), NewColoredHandler(&textBuf, &slog.LevelVar{})} {
+				slog.New(h).WithGroup("http").With("svc", "api").WithGroup("req").
+					Info("served", "id", 7, slog.Group("peer", "ip", "10.0.0.1"))
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(jsonBuf.String(), want) {
+					t.Errorf("JSON output lacks %s: %s", want, jsonBuf.String())
+				}
+			}
+			var m map[string]any
+			if err := json.Unmarshal(jsonBuf.Bytes(), &m); err != nil {
+				t.Fatalf("invalid JSON %q: %v", jsonBuf.String(), err)
+			}
+			// The colored handler uses dotted keys whatever the style
+			if want := "served http.svc=api http.req.id=7 http.req.peer.ip=10.0.0.1"; !strings.Contains(textBuf.String(), want) {
+				t.Errorf("text output = %q, want %q", textBuf.String(), want)
+			}
+		})
+	}
+}
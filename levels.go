@@ -0,0 +1,135 @@
+package glogi
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// customLevel is a level added with RegisterLevel
+type customLevel struct {
+	name  string
+	level slog.Level
+	color string
+}
+
+var (
+	customLevelNames  map[string]customLevel     // By upper-case name
+	customLevelValues map[slog.Level]customLevel // By exact value
+	registeredLevels  = allLevels                // Built-in and custom levels, sorted
+	levelRoundUp      bool                       // Round to the next level instead of the previous one
+)
+
+// RegisterLevel adds a named level between (or beyond) the built-in ones, e.g.
+// an AUDIT level just above INFO. The name is recognized by SetLevel and
+// LOG_LEVEL and shown for records at exactly value in every format; color
+// (an ANSI code or a named color, "" for the color of the surrounding level)
+// is used by the text handler. Built-in names and values can't be redefined.
+// Register levels at startup, before logging.
+//
+//	log.RegisterLevel("AUDIT", log.LevelInfo+1, "magenta")
+//	audit := log.LevelFunc("AUDIT")
+//	audit("user deleted", "id", 42) // ... AUDIT [main.go:12] user deleted id=42
+func RegisterLevel(name string, value slog.Level, color string) error {
+	key := strings.ToUpper(strings.TrimSpace(name))
+	if key == "" {
+		return fmt.Errorf("glogi: empty level name")
+	}
+	if builtinLevel(key) {
+		return fmt.Errorf("glogi: level %q is built in", name)
+	}
+	if slices.Contains(allLevels, value) {
+		return fmt.Errorf("glogi: level value %d is used by %s", int(value), levelName(value))
+	}
+
+	names := make(map[string]customLevel, len(customLevelNames)+1)
+	for k, cl := range customLevelNames {
+		if cl.level != value {
+			names[k] = cl
+		}
+	}
+	values := make(map[slog.Level]customLevel, len(customLevelValues)+1)
+	for v, cl := range customLevelValues {
+		if cl.name != key {
+			values[v] = cl
+		}
+	}
+	cl := customLevel{name: key, level: value, color: parseColor(color)}
+	names[key] = cl
+	values[value] = cl
+	customLevelNames, customLevelValues = names, values
+	registered := slices.Clone(allLevels)
+	for v := range values {
+		registered = append(registered, v)
+	}
+	slices.Sort(registered)
+	registeredLevels = registered
+	updateLevelNameWidth()
+	return nil
+}
+
+// LevelFunc returns a function logging at the level named name (built-in or
+// registered with RegisterLevel) through the global logger. Unknown names log
+// at INFO, with a notice on stderr.
+func LevelFunc(name string) func(msg string, args ...any) {
+	lvl := parseLevel(name)
+	return func(msg string, args ...any) {
+		logWithCaller(context.Background(), lvl, msg, args...)
+	}
+}
+
+// SetLevelRoundUp sets how a value between two levels is named and colored.
+// By default it takes the closest built-in or registered level at or below it,
+// like slog: -6 is TRACE, 2 is INFO, and with an AUDIT level at 1, 3 is AUDIT.
+// Values below TRACE are TRACE. With roundUp, the closest level at or above is
+// used instead (-6 is DEBUG, 2 is WARN) and values above PANIC are PANIC.
+func SetLevelRoundUp(roundUp bool) { levelRoundUp = roundUp }
+
+// registeredLevel returns the built-in or registered level l is shown as
+func registeredLevel(l slog.Level) slog.Level {
+	return roundLevel(registeredLevels, l)
+}
+
+// standardLevel returns the built-in level l is shown as, ignoring
+// registered levels (colors of levels without their own, stats)
+func standardLevel(l slog.Level) slog.Level {
+	return roundLevel(allLevels, l)
+}
+
+// roundLevel returns the level of sorted that l rounds to: the closest one at
+// or below l (at or above with SetLevelRoundUp), clamped to the range
+func roundLevel(sorted []slog.Level, l slog.Level) slog.Level {
+	i, found := slices.BinarySearch(sorted, l)
+	switch {
+	case found:
+		return l
+	case levelRoundUp:
+		return sorted[min(i, len(sorted)-1)]
+	default:
+		return sorted[max(i-1, 0)]
+	}
+}
+
+// builtinLevel reports whether name (upper case) is a built-in level name
+func builtinLevel(name string) bool {
+	switch name {
+	case "TRACE", "DEBUG", "INFO", "WARN", "WARNING", "ERROR", "FATAL", "PANIC":
+		return true
+	}
+	return false
+}
+
+// updateLevelNameWidth pads the level column to the longest level label
+func updateLevelNameWidth() {
+	width := 0
+	for _, l := range allLevels {
+		width = max(width, utf8.RuneCountInString(levelLabel(l)))
+	}
+	for l := range customLevelValues {
+		width = max(width, utf8.RuneCountInString(levelLabel(l)))
+	}
+	levelNameWidth = width
+}
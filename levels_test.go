@@ -0,0 +1,83 @@
+package glogi
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// resetLevels restores the registered levels and rounding after a test
+func resetLevels(t *testing.T) {
+	names, values, registered, roundUp := customLevelNames, customLevelValues, registeredLevels, levelRoundUp
+	t.Cleanup(func() {
+		customLevelNames, customLevelValues, registeredLevels, levelRoundUp = names, values, registered, roundUp
+		updateLevelNameWidth()
+	})
+}
+
+func TestLevelNameBetweenStandardLevels(t *testing.T) {
+	resetLevels(t)
+	tests := []struct {
+		level    slog.Level
+		down, up string
+	}{
+		{LevelTrace - 2, "TRACE", "TRACE"},
+		{LevelTrace, "TRACE", "TRACE"},
+		{-6, "TRACE", "DEBUG"},
+		{-1, "DEBUG", "INFO"},
+		{2, "INFO", "WARN"},
+		{6, "WARN", "ERROR"},
+		{10, "ERROR", "FATAL"},
+		{14, "FATAL", "PANIC"},
+		{LevelPanic + 4, "PANIC", "PANIC"},
+	}
+	for _, tt := range tests {
+		SetLevelRoundUp(false)
+		if got := levelName(tt.level); got != tt.down {
+			t.Errorf("levelName(%d) = %s, want %s", tt.level, got, tt.down)
+		}
+		SetLevelRoundUp(true)
+		if got := levelName(tt.level); got != tt.up {
+			t.Errorf("round up: levelName(%d) = %s, want %s", tt.level, got, tt.up)
+		}
+	}
+}
+
+func TestLevelNameRoundsToRegisteredLevel(t *testing.T) {
+	resetLevels(t)
+	if err := RegisterLevel("AUDIT", LevelInfo+1, ""); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		level    slog.Level
+		down, up string
+	}{
+		{LevelInfo, "INFO", "INFO"},
+		{LevelInfo + 1, "AUDIT", "AUDIT"},
+		{LevelInfo + 3, "AUDIT", "WARN"},
+		{LevelInfo - 1, "DEBUG", "INFO"},
+	}
+	for _, tt := range tests {
+		SetLevelRoundUp(false)
+		if got := levelName(tt.level); got != tt.down {
+			t.Errorf("levelName(%d) = %s, want %s", tt.level, got, tt.down)
+		}
+		SetLevelRoundUp(true)
+		if got := levelName(tt.level); got != tt.up {
+			t.Errorf("round up: levelName(%d) = %s, want %s", tt.level, got, tt.up)
+		}
+	}
+}
+
+func TestIntermediateLevelInOutput(t *testing.T) {
+	resetLevels(t)
+	var buf bytes.Buffer
+	lv := &slog.LevelVar{}
+	lv.Set(LevelTrace)
+	slog.New(NewColoredHandler(&buf, lv)).Log(context.Background(), -6, "between trace and debug")
+	if !strings.Contains(buf.String(), "] TRACE ") {
+		t.Errorf("output = %q", buf.String())
+	}
+}
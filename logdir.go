@@ -0,0 +1,93 @@
+package glogi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// Files written by SetLogDir
+const (
+	logDirAppFile   = "app.log"   // Every record
+	logDirErrorFile = "error.log" // ERROR and above
+)
+
+// logDirFiles are the files opened by SetLogDir, closed by Close
+var logDirFiles []io.Closer
+
+// SetLogDir writes the global logger's records to files in dir, created if
+// needed: every record to app.log, and ERROR and above also to error.log.
+// Files are opened for appending, written without colors and closed by Close.
+// Call it once, after Init; it replaces the current output.
+//
+//	log.Init()
+//	if err := log.SetLogDir("/var/log/myapp"); err != nil { ... }
+//	defer log.Close()
+func SetLogDir(dir string) error {
+	if logDirFiles != nil {
+		return errors.New("glogi: log dir already set")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("glogi: create log dir: %w", err)
+	}
+	app, err := openLogFile(filepath.Join(dir, logDirAppFile))
+	if err != nil {
+		return err
+	}
+	errLog, err := openLogFile(filepath.Join(dir, logDirErrorFile))
+	if err != nil {
+		_ = app.Close()
+		return err
+	}
+	logDirFiles = []io.Closer{app, errLog}
+	SetOutput(app)
+	AddOutput(&minLevelWriter{w: errLog, min: LevelError}, false)
+	return nil
+}
+
+// openLogFile opens path for appending, creating it if needed
+func openLogFile(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("glogi: open log file: %w", err)
+	}
+	return f, nil
+}
+
+// closeLogDir closes the files opened by SetLogDir
+func closeLogDir() []error {
+	var errs []error
+	for _, c := range logDirFiles {
+		errs = append(errs, c.Close())
+	}
+	logDirFiles = nil
+	return errs
+}
+
+// minLevelWriter passes records of level min and above to w and drops the rest
+type minLevelWriter struct {
+	w   io.Writer
+	min slog.Level
+}
+
+// Write writes p as an INFO record
+func (m *minLevelWriter) Write(p []byte) (int, error) {
+	return m.writeLevel(LevelInfo, p)
+}
+
+// writeLevel writes a record of level l if it is at or above the minimum
+func (m *minLevelWriter) writeLevel(l slog.Level, p []byte) (int, error) {
+	if l < m.min {
+		return len(p), nil
+	}
+	if lw, ok := m.w.(levelWriter); ok {
+		return lw.writeLevel(l, p)
+	}
+	return m.w.Write(p)
+}
+
+// Sync flushes or syncs the underlying writer (see Flush)
+func (m *minLevelWriter) Sync() error { return syncWriter(m.w) }
@@ -0,0 +1,100 @@
+package glogi
+
+import (
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// NewLogfmtHandler creates a handler writing strict logfmt, one record per line:
+//
+//	time=2025-12-26T15:04:05.123+01:00 level=INFO source=main.go:18 msg="server started" port=8080
+//
+// It shares the text handler's features (level names, attr rendering and
+// quoting, colors on terminals, hooks); only the layout differs. Keys are
+// always joined with "=" and separated by spaces, regardless of
+// SetKeyValueDelimiter and SetAttrSeparator. LOG_FORMAT=logfmt selects it for
+// the global logger.
+func NewLogfmtHandler(w io.Writer, level *slog.LevelVar) *ColoredHandler {
+	h := NewColoredHandler(w, level)
+	h.logfmt = true
+	return h
+}
+
+// formatLogfmtLine renders a record as logfmt, with or without colors.
+// Compact mode omits time and source, like the text format.
+func formatLogfmtLine(r slog.Record, loc string, fields [][]attrField, colors bool) []byte {
+	colors = colors && r.Level >= colorMinLevel
+	var b strings.Builder
+	b.WriteString(linePrefix)
+	field := func(key, value, color string) {
+		if b.Len() > len(linePrefix) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(colorize(logfmtKey(key), colorKey, colors))
+		b.WriteByte('=')
+		b.WriteString(colorize(value, color, colors))
+	}
+
+	if !compact {
+		field(timeKey, recordTime(r.Time).Format(time.RFC3339Nano), colorValue)
+	}
+	field(levelKey, logfmtQuote(levelLabel(r.Level)), colorForLevel(r.Level))
+	if loc != "" {
+		field(sourceKey, logfmtQuote(loc), colorSource)
+	}
+	field(messageKey, logfmtQuote(r.Message), "")
+	for _, fs := range fields {
+		for _, f := range fs {
+			color := colorValue
+			if f.color != "" {
+				color = f.color
+			}
+			if f.change {
+				field(f.key+"."+ChangeOldKey, logfmtValue(f.value), colorError)
+				field(f.key+"."+ChangeNewKey, logfmtValue(f.newValue), defaultColorGreen)
+			} else if f.multiline {
+				field(f.key, logfmtQuote(f.value), color)
+			} else {
+				field(f.key, logfmtValue(f.value), color)
+			}
+		}
+	}
+	b.WriteString(lineSuffix)
+	b.WriteString(lineTerminator)
+	return []byte(b.String())
+}
+
+// logfmtQuote quotes s if it contains characters that end a logfmt value
+func logfmtQuote(s string) string {
+	if needsQuoting(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// logfmtValue makes a rendered attr value valid logfmt. formatValue output is
+// either quoted already or safe, except for multi-line values like Stack.
+func logfmtValue(s string) string {
+	if strings.HasPrefix(s, `"`) {
+		return s
+	}
+	return logfmtQuote(s)
+}
+
+// logfmtKey replaces characters that would break logfmt parsing (spaces, '=',
+// quotes, control characters) with '_'
+func logfmtKey(k string) string {
+	if k == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '=' || r == '"' || !unicode.IsPrint(r) {
+			return '_'
+		}
+		return r
+	}, k)
+}
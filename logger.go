@@ -0,0 +1,253 @@
+package glogi
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"runtime"
+)
+
+// Logger is a logger instance with its own handler and level, so several
+// loggers can write to different destinations in one process (e.g. an audit
+// file next to the application log). Its methods mirror the package-level
+// functions, which delegate to a default instance created by Init.
+//
+// A nil *Logger is valid: its methods fall back to the global logger, so an
+// optional logger field can be used without nil checks.
+type Logger struct {
+	sl     *slog.Logger
+	level  *slog.LevelVar
+	worker string // Worker label of records without a context label (LoggerForWorker)
+}
+
+// New creates a logger writing to w with the given minimum level
+// (TRACE, DEBUG, INFO, WARN, ERROR). The output format follows LOG_FORMAT
+// and the global formatting settings (colors, source width, ...).
+//
+//	audit := log.New(auditFile, "INFO")
+//	audit.Info("user deleted", "id", 42)
+func New(w io.Writer, level string) *Logger {
+	lv := &slog.LevelVar{}
+	lv.Set(parseLevel(level))
+	return &Logger{sl: slog.New(newFormatHandler(w, lv)), level: lv}
+}
+
+// SetLevel changes the logger's minimum level at runtime.
+// On a nil Logger it changes the global level.
+func (l *Logger) SetLevel(level string) {
+	if l == nil || l.level == nil {
+		SetLevel(level)
+		return
+	}
+	l.level.Set(parseLevel(level))
+}
+
+// With returns a child logger that adds args (key-value pairs or slog.Attr)
+// to every record. It shares the parent's destination and level.
+//
+//	svc := log.With("service", "api", "instance", 3)
+//	svc.Info("started") // ... started service=api instance=3
+func (l *Logger) With(args ...any) *Logger {
+	if l == nil {
+		ensureInit()
+		l = defaultLogger()
+	}
+	return &Logger{sl: l.slogger().With(args...), level: l.level, worker: l.worker}
+}
+
+// WithGroup returns a child logger that qualifies attrs added to its records
+// with name (name.key=value). It shares the parent's destination and level.
+func (l *Logger) WithGroup(name string) *Logger {
+	if l == nil {
+		ensureInit()
+		l = defaultLogger()
+	}
+	return &Logger{sl: l.slogger().WithGroup(name), level: l.level, worker: l.worker}
+}
+
+// levelHandler is a glogi handler that can be copied with its own level
+type levelHandler interface {
+	withLevel(lv *slog.LevelVar) slog.Handler
+}
+
+// WithLevel returns a child logger with its own minimum level, e.g. DEBUG for
+// one operation while the rest of the app stays at INFO. Unlike SetLevel it
+// changes nothing global, so other goroutines are unaffected, and there is
+// nothing to restore: the level ends with the child. Changing the child's
+// level (SetLevel) doesn't affect the parent. It shares the parent's
+// destination and attrs. A Logger on a non-glogi handler is returned as is.
+//
+//	dbg := log.WithLevel(log.LevelDebug)
+//	dbg.Debug("retrying", "attempt", n) // emitted at global INFO
+func (l *Logger) WithLevel(level slog.Level) *Logger {
+	if l == nil {
+		ensureInit()
+		l = defaultLogger()
+	}
+	h, ok := l.slogger().Handler().(levelHandler)
+	if !ok {
+		return l
+	}
+	lv := &slog.LevelVar{}
+	lv.Set(level)
+	return &Logger{sl: slog.New(h.withLevel(lv)), level: lv, worker: l.worker}
+}
+
+// WithLevel returns a logger derived from the global logger with its own
+// minimum level; see Logger.WithLevel
+func WithLevel(level slog.Level) *Logger {
+	return (*Logger)(nil).WithLevel(level)
+}
+
+// With returns a logger derived from the global logger that adds args to
+// every record. Changing its level changes the global level.
+func With(args ...any) *Logger {
+	return (*Logger)(nil).With(args...)
+}
+
+// ErrorKey is the attr key used by Err and WithError
+const ErrorKey = "error"
+
+// Err returns an error=err attr, or an empty attr that every handler skips
+// when err is nil, so call sites use one key and never print error=<nil>:
+//
+//	log.Error("save failed", log.Err(err), "id", id)
+func Err(err error) slog.Attr {
+	if err == nil {
+		return slog.Attr{}
+	}
+	return slog.Any(ErrorKey, err)
+}
+
+// WithError returns a child logger that adds error=err to every record, or l
+// itself (the global logger for a nil Logger) when err is nil
+func (l *Logger) WithError(err error) *Logger {
+	if err == nil {
+		if l == nil {
+			ensureInit()
+			return defaultLogger()
+		}
+		return l
+	}
+	return l.With(Err(err))
+}
+
+// WithError returns a logger derived from the global logger that adds
+// error=err to every record (nothing when err is nil)
+func WithError(err error) *Logger {
+	return (*Logger)(nil).WithError(err)
+}
+
+// SetLevelValue sets the logger's minimum level to an exact value.
+// On a nil Logger it changes the global level.
+func (l *Logger) SetLevelValue(lvl slog.Level) {
+	if l == nil || l.level == nil {
+		SetLevelValue(lvl)
+		return
+	}
+	l.level.Set(lvl)
+}
+
+// GetLevel returns the logger's minimum level (the global level for a nil Logger)
+func (l *Logger) GetLevel() slog.Level {
+	if l == nil || l.level == nil {
+		return GetLevel()
+	}
+	return l.level.Level()
+}
+
+// slogger returns the underlying slog logger, or the global one for a nil Logger
+func (l *Logger) slogger() *slog.Logger {
+	if l == nil || l.sl == nil {
+		ensureInit()
+		return logger()
+	}
+	return l.sl
+}
+
+// log emits a record attributed to the user's call site.
+// depth is the number of glogi frames between the public function and log
+// (0 for Logger methods). A nil ctx is treated as context.Background().
+func (l *Logger) log(ctx context.Context, depth int, lvl slog.Level, msg string, args ...any) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if l != nil && l.worker != "" {
+		if _, ok := WorkerLabel(ctx); !ok {
+			ctx = WithWorkerLabel(ctx, l.worker)
+		}
+	}
+	sl := l.slogger()
+	if !sl.Enabled(ctx, lvl) {
+		return
+	}
+
+	var pcs [1]uintptr
+	if captureSource(lvl) {
+		runtime.Callers(3+depth+callerSkip, pcs[:]) // skip: Callers, log, public func (+ depth)
+	}
+
+	args, ok := checkArgs(msg, pcs[0], args)
+	if !ok {
+		return
+	}
+	r := slog.NewRecord(clock(), lvl, msg, pcs[0])
+	r.Add(args...)
+	_ = sl.Handler().Handle(ctx, r)
+}
+
+// Trace logs at TRACE level
+func (l *Logger) Trace(msg string, args ...any) {
+	l.log(context.Background(), 0, LevelTrace, msg, args...)
+}
+
+// Debug logs at DEBUG level
+func (l *Logger) Debug(msg string, args ...any) {
+	l.log(context.Background(), 0, LevelDebug, msg, args...)
+}
+
+// Info logs at INFO level
+func (l *Logger) Info(msg string, args ...any) {
+	l.log(context.Background(), 0, LevelInfo, msg, args...)
+}
+
+// Warn logs at WARN level
+func (l *Logger) Warn(msg string, args ...any) {
+	l.log(context.Background(), 0, LevelWarn, msg, args...)
+}
+
+// Error logs at ERROR level
+func (l *Logger) Error(msg string, args ...any) {
+	l.log(context.Background(), 0, LevelError, msg, args...)
+}
+
+// Fatal logs at FATAL level and exits (see RegisterExitHandler)
+func (l *Logger) Fatal(msg string, args ...any) {
+	l.log(context.Background(), 0, LevelFatal, msg, args...)
+	exit()
+}
+
+// TraceContext logs at TRACE level with a context
+func (l *Logger) TraceContext(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, 0, LevelTrace, msg, args...)
+}
+
+// DebugContext logs at DEBUG level with a context
+func (l *Logger) DebugContext(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, 0, LevelDebug, msg, args...)
+}
+
+// InfoContext logs at INFO level with a context
+func (l *Logger) InfoContext(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, 0, LevelInfo, msg, args...)
+}
+
+// WarnContext logs at WARN level with a context
+func (l *Logger) WarnContext(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, 0, LevelWarn, msg, args...)
+}
+
+// ErrorContext logs at ERROR level with a context
+func (l *Logger) ErrorContext(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, 0, LevelError, msg, args...)
+}
@@ -0,0 +1,42 @@
+//go:build glogi_otel
+
+package glogi
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// OpenTelemetry integration is optional and compiled only with the glogi_otel build tag:
+//
+//	go build -tags glogi_otel
+//
+// glogi does not require the otel module itself; the application already
+// depends on go.opentelemetry.io/otel for its tracing setup.
+
+var otelOnce sync.Once
+
+// EnableOTelTrace adds trace_id and span_id attrs, taken from the active span
+// in the context, to every record logged with a context (InfoContext,
+// slog.InfoContext, ...). Records without a valid span context are unaffected.
+// Calling it more than once has no further effect.
+func EnableOTelTrace() {
+	otelOnce.Do(func() {
+		RegisterContextExtractor(otelTraceAttrs)
+	})
+}
+
+// otelTraceAttrs returns the trace and span IDs of the span in ctx
+func otelTraceAttrs(ctx context.Context) []slog.Attr {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+	return []slog.Attr{
+		slog.String("trace_id", sc.TraceID().String()),
+		slog.String("span_id", sc.SpanID().String()),
+	}
+}
@@ -0,0 +1,296 @@
+package glogi
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// outputRef is a handler destination that can be swapped at runtime without
+// data races. It is shared by a handler and all handlers derived from it via
+// WithAttrs/WithGroup, so SetOutput affects all of them.
+type outputRef struct {
+	p    atomic.Pointer[writerBox]
+	errP atomic.Pointer[writerBox]   // Destination for WARN and above in split mode (nil: use p)
+	tees atomic.Pointer[[]teeOutput] // Extra destinations added with AddOutput
+}
+
+// teeOutput is an extra destination receiving every record, formatted with
+// or without colors independently of the main writer
+type teeOutput struct {
+	box     *writerBox
+	colored bool
+}
+
+// writerBox wraps the writer so interface values can be stored atomically.
+// tty records whether the writer is a terminal (colors are on by default);
+// mu serializes writes to w.
+type writerBox struct {
+	w   io.Writer
+	tty bool
+	mu  *sync.Mutex
+}
+
+// stdoutLock and stderrLock serialize the writes of every handler writing to
+// the process's standard streams, which independent loggers commonly share
+var stdoutLock, stderrLock sync.Mutex
+
+// writeLock returns the mutex guarding writes to a new destination w: the
+// shared one of os.Stdout or os.Stderr, otherwise one owned by the caller's
+// writerBox. Handlers derived from one another (With, WithGroup, ...) share
+// their outputRef and so their lock; independent loggers given the same
+// other writer must serialize it themselves.
+func writeLock(w io.Writer) *sync.Mutex {
+	switch w {
+	case io.Writer(os.Stdout):
+		return &stdoutLock
+	case io.Writer(os.Stderr):
+		return &stderrLock
+	}
+	return &sync.Mutex{}
+}
+
+func newOutputRef(w io.Writer) *outputRef {
+	o := &outputRef{}
+	o.Store(w)
+	return o
+}
+
+// Load returns the current writer
+func (o *outputRef) Load() io.Writer {
+	return o.p.Load().w
+}
+
+// IsTerminal reports whether the current writer is a terminal
+func (o *outputRef) IsTerminal() bool {
+	return o.p.Load().tty
+}
+
+// Store replaces the writer, detecting whether it is a terminal
+func (o *outputRef) Store(w io.Writer) {
+	o.p.Store(&writerBox{w: w, tty: isTerminal(w), mu: writeLock(w)})
+}
+
+// storeAs replaces the writer, inheriting the terminal flag of another
+// destination (used for private buffers that end up on that destination, like Batch)
+func (o *outputRef) storeAs(w io.Writer, tty bool) {
+	o.p.Store(&writerBox{w: w, tty: tty, mu: &sync.Mutex{}})
+}
+
+// Write writes one formatted record atomically: the lock covers only the
+// Write call, so concurrent records never interleave within a line
+func (o *outputRef) Write(p []byte) (int, error) {
+	box := o.p.Load()
+	box.mu.Lock()
+	n, err := box.w.Write(p)
+	box.mu.Unlock()
+	if err != nil {
+		reportWriteError(err)
+	}
+	return n, err
+}
+
+// storeError sets the destination for WARN and above; nil sends all levels to the main writer
+func (o *outputRef) storeError(w io.Writer) {
+	if w == nil {
+		o.errP.Store(nil)
+		return
+	}
+	o.errP.Store(&writerBox{w: w, tty: isTerminal(w), mu: writeLock(w)})
+}
+
+// levelWriter is a destination that needs each record's level, like the
+// syslog writer mapping levels to severities
+type levelWriter interface {
+	writeLevel(l slog.Level, p []byte) (int, error)
+}
+
+// writeLevel writes a record of level l, to the error destination for WARN
+// and above when one is set
+func (o *outputRef) writeLevel(l slog.Level, p []byte) (int, error) {
+	box := o.p.Load()
+	if l >= LevelWarn {
+		if eb := o.errP.Load(); eb != nil {
+			box = eb
+		}
+	}
+	n, err := box.write(l, p)
+	if err != nil {
+		reportWriteError(err)
+	}
+	return n, err
+}
+
+// write writes a record of level l to the box's writer under its lock
+func (b *writerBox) write(l slog.Level, p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if lw, ok := b.w.(levelWriter); ok {
+		return lw.writeLevel(l, p)
+	}
+	return b.w.Write(p)
+}
+
+// addTee adds an extra destination for every record
+func (o *outputRef) addTee(w io.Writer, colored bool) {
+	tee := teeOutput{box: &writerBox{w: w, tty: isTerminal(w), mu: writeLock(w)}, colored: colored}
+	for {
+		old := o.tees.Load()
+		var tees []teeOutput
+		if old != nil {
+			tees = append(tees, *old...)
+		}
+		tees = append(tees, tee)
+		if o.tees.CompareAndSwap(old, &tees) {
+			return
+		}
+	}
+}
+
+// writeTees writes a record of level l to the extra destinations. format
+// renders the record with or without colors; each style is rendered at most
+// once. Returns the first write error.
+func (o *outputRef) writeTees(l slog.Level, format func(colored bool) []byte) error {
+	tees := o.tees.Load()
+	if tees == nil {
+		return nil
+	}
+	var rendered [2][]byte
+	var firstErr error
+	for _, tee := range *tees {
+		i := 0
+		if tee.colored {
+			i = 1
+		}
+		if rendered[i] == nil {
+			rendered[i] = format(tee.colored)
+		}
+		if _, err := tee.box.write(l, rendered[i]); err != nil {
+			reportWriteError(err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// errorHandler is called when writing a record fails; nil uses the stderr notice
+var errorHandler atomic.Pointer[func(error)]
+
+// reportingWriteError is set while the error handler runs, so a handler that
+// logs (and fails again) doesn't recurse
+var reportingWriteError atomic.Bool
+
+// SetErrorHandler sets a function called when writing a record to an output
+// fails (e.g. ENOSPC on a log file), so a broken logging pipeline is noticed
+// instead of records vanishing silently. By default a short notice is written
+// to os.Stderr; nil restores it. The handler may log: write failures while it
+// runs are not reported again.
+func SetErrorHandler(fn func(error)) {
+	if fn == nil {
+		errorHandler.Store(nil)
+		return
+	}
+	errorHandler.Store(&fn)
+}
+
+// reportWriteError passes a write error to the error handler
+func reportWriteError(err error) {
+	if !reportingWriteError.CompareAndSwap(false, true) {
+		return
+	}
+	defer reportingWriteError.Store(false)
+	if fn := errorHandler.Load(); fn != nil {
+		(*fn)(err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "glogi: write failed: %v\n", err)
+}
+
+var (
+	splitOutput bool                  // WARN and above go to errorOutput
+	errorOutput io.Writer = os.Stderr // Destination for WARN and above in split mode
+)
+
+// SetSplitOutput sends WARN and above to the error output (os.Stderr unless set
+// with SetErrorOutput) and lower levels to the normal output, as expected by
+// Kubernetes and systemd severity handling. The format is the same on both
+// streams. LOG_SPLIT_STREAMS=1 enables it from env.
+func SetSplitOutput(enabled bool) {
+	ensureInit()
+	splitOutput = enabled
+	applySplitOutput()
+}
+
+// SetErrorOutput sets the destination for WARN and above and enables split output
+func SetErrorOutput(w io.Writer) {
+	ensureInit()
+	errorOutput = w
+	splitOutput = true
+	applySplitOutput()
+}
+
+// applySplitOutput updates the global logger's error destination
+func applySplitOutput() {
+	h, ok := primaryHandler().(writerHandler)
+	if !ok {
+		return
+	}
+	if splitOutput {
+		h.setErrorOutput(errorOutput)
+	} else {
+		h.setErrorOutput(nil)
+	}
+}
+
+// SetOutput redirects the global logger to w (e.g. a file or a buffer in tests).
+// It is safe to call while other goroutines are logging. Called before Init,
+// w is used from the start instead of LOG_FILE or stdout. It ends async output
+// (SetAsync), writing the queued records to the old destination first.
+func SetOutput(w io.Writer) {
+	if setPending(func() { pendingOutput = w }) {
+		return
+	}
+	asyncMu.Lock()
+	if asyncOut != nil {
+		_ = asyncOut.close() // Queued records still go to the old destination
+		asyncOut = nil
+	}
+	defaultLogger().SetOutput(w)
+	asyncMu.Unlock()
+	rebindSlogDefault()
+}
+
+// SetOutput redirects the logger to w. It is safe to call while other
+// goroutines are logging. On a nil Logger it redirects the global logger.
+func (l *Logger) SetOutput(w io.Writer) {
+	if h, ok := unwrapHandler(l.slogger().Handler()).(writerHandler); ok {
+		h.setOutput(w)
+	}
+}
+
+// AddOutput adds a destination that receives every record in addition to the
+// current output, e.g. a file next to the console. colored selects whether
+// records written to w carry color codes, independently of the main output;
+// each style is formatted once per record. Split output (SetSplitOutput) and
+// SetOutput affect only the main output.
+//
+//	f, _ := os.OpenFile("app.log", os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+//	log.AddOutput(f, false) // colored console, plain file
+func AddOutput(w io.Writer, colored bool) {
+	ensureInit()
+	defaultLogger().AddOutput(w, colored)
+}
+
+// AddOutput adds a destination receiving every record of the logger (and all
+// loggers sharing its output) in addition to the current one. On a nil Logger
+// it adds it to the global logger.
+func (l *Logger) AddOutput(w io.Writer, colored bool) {
+	if h, ok := unwrapHandler(l.slogger().Handler()).(writerHandler); ok {
+		h.addOutput(w, colored)
+	}
+}
@@ -0,0 +1,105 @@
+package glogi
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSetOutputRedirects(t *testing.T) {
+	first := captureOutput(t, "text")
+	Info("before")
+	var second bytes.Buffer
+	SetOutput(&second)
+	Info("after")
+
+	if !strings.Contains(first.String(), "before") || strings.Contains(first.String(), "after") {
+		t.Errorf("first output = %q", first.String())
+	}
+	if !strings.Contains(second.String(), "after") || strings.Contains(second.String(), "before") {
+		t.Errorf("second output = %q", second.String())
+	}
+}
+
+func TestLoggerSetOutput(t *testing.T) {
+	var first, second bytes.Buffer
+	l := New(&first, "INFO")
+	l.Info("before")
+	l.SetOutput(&second)
+	l.Info("after")
+
+	if !strings.Contains(first.String(), "before") || strings.Contains(first.String(), "after") {
+		t.Errorf("first output = %q", first.String())
+	}
+	if !strings.Contains(second.String(), "after") {
+		t.Errorf("second output = %q", second.String())
+	}
+}
+
+func TestSetOutputWhileLogging(t *testing.T) {
+	var a, b bytes.Buffer
+	captureOutput(t, "text")
+	SetOutput(&a)
+
+	const goroutines, lines = 8, 200
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < lines; i++ {
+				Info("tick")
+			}
+		}()
+	}
+	for i := 0; i < 100; i++ {
+		if i%2 == 0 {
+			SetOutput(&b)
+		} else {
+			SetOutput(&a)
+		}
+	}
+	wg.Wait()
+
+	if n := strings.Count(a.String(), "tick") + strings.Count(b.String(), "tick"); n != goroutines*lines {
+		t.Errorf("got %d records, want %d", n, goroutines*lines)
+	}
+}
+
+func TestWriteLockSharedOnlyForStandardStreams(t *testing.T) {
+	if writeLock(os.Stdout) != writeLock(os.Stdout) || writeLock(os.Stderr) != writeLock(os.Stderr) {
+		t.Error("standard streams don't share their lock")
+	}
+	var buf bytes.Buffer
+	if writeLock(&buf) == writeLock(&buf) {
+		t.Error("writeLock returned a shared lock for an ordinary writer")
+	}
+}
+
+func TestOutputNotRetainedAfterLogger(t *testing.T) {
+	collected := make(chan struct{})
+	func() {
+		buf := &bytes.Buffer{}
+		runtime.SetFinalizer(buf, func(*bytes.Buffer) { close(collected) })
+		l := New(buf, "INFO")
+		l.Info("short-lived")
+		l.SetOutput(io.Discard)
+	}()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		runtime.GC()
+		select {
+		case <-collected:
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("writer still reachable after its logger was dropped")
+		}
+	}
+}
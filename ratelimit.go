@@ -0,0 +1,94 @@
+package glogi
+
+import (
+	"container/list"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxRateLimitEntries bounds how many rate-limited messages are tracked at
+// once; the least recently logged one is forgotten first
+const maxRateLimitEntries = 1024
+
+var (
+	rateLimitMu     sync.Mutex
+	rateLimits      = map[string]time.Duration{} // Message -> minimum interval
+	rateLimitActive atomic.Bool                  // Whether any limit is set (fast path)
+	rateLimitLRU    = list.New()                 // *rateLimitEntry, most recent first
+	rateLimitIndex  = map[string]*list.Element{}
+)
+
+// rateLimitEntry is the current window of a rate-limited message
+type rateLimitEntry struct {
+	msg        string
+	start      time.Time // When the last emitted record was logged
+	suppressed uint64    // Records dropped since then
+}
+
+// SetMessageRateLimit emits the record with exactly this message at most once
+// per interval, whatever its level; records in between are dropped and
+// counted, and the next emitted one carries a suppressed=N attr. Other
+// messages are unaffected. per <= 0 removes the limit.
+//
+//	log.SetMessageRateLimit("retrying connection", time.Second)
+func SetMessageRateLimit(msg string, per time.Duration) {
+	rateLimitMu.Lock()
+	defer rateLimitMu.Unlock()
+	if per <= 0 {
+		delete(rateLimits, msg)
+		if e, ok := rateLimitIndex[msg]; ok {
+			rateLimitLRU.Remove(e)
+			delete(rateLimitIndex, msg)
+		}
+	} else {
+		rateLimits[msg] = per
+	}
+	rateLimitActive.Store(len(rateLimits) > 0)
+}
+
+// rateLimitRecord reports whether r should be emitted under the message rate
+// limits. An emitted record gets a suppressed attr if records were dropped
+// in its window.
+func rateLimitRecord(r *slog.Record) bool {
+	if !rateLimitActive.Load() {
+		return true
+	}
+	rateLimitMu.Lock()
+	per, ok := rateLimits[r.Message]
+	if !ok {
+		rateLimitMu.Unlock()
+		return true
+	}
+
+	var e *rateLimitEntry
+	if el, ok := rateLimitIndex[r.Message]; ok {
+		rateLimitLRU.MoveToFront(el)
+		e = el.Value.(*rateLimitEntry)
+	} else {
+		if rateLimitLRU.Len() >= maxRateLimitEntries {
+			oldest := rateLimitLRU.Back()
+			rateLimitLRU.Remove(oldest)
+			delete(rateLimitIndex, oldest.Value.(*rateLimitEntry).msg)
+		}
+		e = &rateLimitEntry{msg: r.Message}
+		rateLimitIndex[r.Message] = rateLimitLRU.PushFront(e)
+	}
+
+	now := clock()
+	if !e.start.IsZero() && now.Sub(e.start) < per {
+		e.suppressed++
+		rateLimitMu.Unlock()
+		return false
+	}
+	suppressed := e.suppressed
+	e.start, e.suppressed = now, 0
+	rateLimitMu.Unlock()
+
+	if suppressed > 0 {
+		*r = r.Clone()
+		r.AddAttrs(slog.Uint64("suppressed", suppressed))
+	}
+	return true
+}
@@ -0,0 +1,41 @@
+package glogi
+
+import (
+	"log/slog"
+	"strings"
+)
+
+// redactedValue replaces the value of attributes with redacted keys
+const redactedValue = "***REDACTED***"
+
+// redactKeys holds lowercased attribute keys whose values are never logged
+var redactKeys map[string]struct{}
+
+// SetRedactKeys sets attribute keys whose values are replaced with
+// "***REDACTED***" in every handler, including attrs inside groups and
+// handler-level attrs. Matching is exact and case-insensitive on the attr's
+// own key ("password" matches user.password but not password_hint).
+// Call with no keys to disable. LOG_REDACT_KEYS=password,token sets it from env.
+func SetRedactKeys(keys ...string) {
+	m := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		if k = strings.ToLower(strings.TrimSpace(k)); k != "" {
+			m[k] = struct{}{}
+		}
+	}
+	if len(m) == 0 {
+		m = nil
+	}
+	redactKeys = m
+}
+
+// redactAttr replaces the value of a with the redaction marker if its key is redacted
+func redactAttr(a slog.Attr) slog.Attr {
+	if redactKeys == nil {
+		return a
+	}
+	if _, ok := redactKeys[strings.ToLower(a.Key)]; ok {
+		return slog.String(a.Key, redactedValue)
+	}
+	return a
+}
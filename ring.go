@@ -0,0 +1,93 @@
+package glogi
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Record is a record kept by a RingHandler, which sets Time, Level and the
+// formatted Line, or sent by a ChannelHandler, which sets the structured
+// fields (Time, Level, Message, Source, Attrs) and leaves Line empty
+type Record struct {
+	Time    time.Time   // When the record was logged (RingHandler: stored)
+	Level   slog.Level  // LevelInfo for lines written without a level
+	Line    string      // Formatted line, without the line terminator
+	Message string      // Log message
+	Source  string      // Source location ("main.go:18"), "" when not captured
+	Attrs   []slog.Attr // Record attrs, then With and context attrs; groups nested
+}
+
+// RingHandler keeps the last records in memory, e.g. to serve them from a
+// /debug/logs endpoint. When full, the oldest record is evicted. It is safe
+// for concurrent use.
+//
+// It can be used as an extra output of the global logger, receiving the same
+// records as the main destination:
+//
+//	ring := log.NewRingHandler(1000)
+//	log.AddOutput(ring, false)
+//	http.HandleFunc("/debug/logs", func(w http.ResponseWriter, _ *http.Request) {
+//	    ring.Dump(w)
+//	})
+//
+// or as a slog.Handler of its own (slog.New(ring)), formatting records in the
+// text format at every level.
+type RingHandler struct {
+	slog.Handler // Text handler writing into the ring
+
+	mu      sync.Mutex
+	records []Record
+	next    int  // Index the next record is stored at
+	full    bool // records has wrapped around
+}
+
+// NewRingHandler creates a ring keeping the last capacity records (at least one)
+func NewRingHandler(capacity int) *RingHandler {
+	rh := &RingHandler{records: make([]Record, max(capacity, 1))}
+	lv := &slog.LevelVar{}
+	lv.Set(LevelTrace)
+	rh.Handler = NewColoredHandler(rh, lv)
+	return rh
+}
+
+// Write stores p as an INFO record
+func (rh *RingHandler) Write(p []byte) (int, error) {
+	return rh.writeLevel(LevelInfo, p)
+}
+
+// writeLevel stores a formatted record of level l
+func (rh *RingHandler) writeLevel(l slog.Level, p []byte) (int, error) {
+	rec := Record{Time: clock(), Level: l, Line: strings.TrimRight(string(p), "\r\n")}
+	rh.mu.Lock()
+	rh.records[rh.next] = rec
+	rh.next = (rh.next + 1) % len(rh.records)
+	if rh.next == 0 {
+		rh.full = true
+	}
+	rh.mu.Unlock()
+	return len(p), nil
+}
+
+// Records returns a copy of the stored records, oldest first
+func (rh *RingHandler) Records() []Record {
+	rh.mu.Lock()
+	defer rh.mu.Unlock()
+	if !rh.full {
+		return append([]Record(nil), rh.records[:rh.next]...)
+	}
+	return append(append([]Record(nil), rh.records[rh.next:]...), rh.records[:rh.next]...)
+}
+
+// Dump writes the stored records to w, oldest first, one per line
+func (rh *RingHandler) Dump(w io.Writer) error {
+	var b strings.Builder
+	for _, rec := range rh.Records() {
+		b.WriteString(rec.Line)
+		b.WriteByte('\n')
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,122 @@
+// Package rotate provides a size-based rotating log file for glogi.
+// Any io.Writer can be used as glogi output; this is a minimal built-in option
+// for services that don't run under a log collector.
+package rotate
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// RotatingWriter is an io.Writer appending to a file that is rotated when it
+// would exceed maxBytes: app.log becomes app.log.1, app.log.1 becomes
+// app.log.2 and so on, keeping at most maxBackups old files.
+// It is safe for concurrent use.
+type RotatingWriter struct {
+	mu         sync.Mutex
+	path       string
+	maxBytes   int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// NewRotatingWriter opens (or creates) path for appending. A maxBytes <= 0
+// disables rotation; maxBackups is the number of rotated files kept (0 keeps none).
+//
+//	w, err := rotate.NewRotatingWriter("/var/log/app.log", 100<<20, 5)
+//	if err != nil { ... }
+//	log.SetOutput(w)
+func NewRotatingWriter(path string, maxBytes int64, maxBackups int) (*RotatingWriter, error) {
+	w := &RotatingWriter{path: path, maxBytes: maxBytes, maxBackups: maxBackups}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// open opens the current file for appending and records its size
+func (w *RotatingWriter) open() error {
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.file, w.size = f, fi.Size()
+	return nil
+}
+
+// Write appends p, rotating first if p would push the file past maxBytes.
+// A single record larger than maxBytes is still written whole.
+func (w *RotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+	if w.maxBytes > 0 && w.size > 0 && w.size+int64(len(p)) > w.maxBytes {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// rotate shifts the backups, moves the current file to path.1 and reopens path
+func (w *RotatingWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	w.file = nil
+
+	if w.maxBackups <= 0 {
+		if err := os.Remove(w.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	} else {
+		os.Remove(w.backup(w.maxBackups)) // Oldest backup falls off
+		for i := w.maxBackups - 1; i >= 1; i-- {
+			if err := os.Rename(w.backup(i), w.backup(i+1)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		if err := os.Rename(w.path, w.backup(1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return w.open()
+}
+
+// backup returns the name of the i-th rotated file
+func (w *RotatingWriter) backup(i int) string {
+	return fmt.Sprintf("%s.%d", w.path, i)
+}
+
+// Sync commits the current file to stable storage
+func (w *RotatingWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	return w.file.Sync()
+}
+
+// Close closes the current file. Further writes fail with os.ErrClosed.
+func (w *RotatingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
@@ -0,0 +1,172 @@
+package rotate
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newWriter opens a RotatingWriter in a temporary directory and closes it after the test
+func newWriter(t *testing.T, maxBytes int64, maxBackups int) (*RotatingWriter, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "app.log")
+	w, err := NewRotatingWriter(path, maxBytes, maxBackups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = w.Close() })
+	return w, path
+}
+
+// write writes each record to w
+func write(t *testing.T, w *RotatingWriter, records ...string) {
+	t.Helper()
+	for _, r := range records {
+		if _, err := w.Write([]byte(r)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// content returns the content of a file ("" if it doesn't exist)
+func content(t *testing.T, path string) string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+// exists reports whether path exists
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestRotatesWhenSizeExceeded(t *testing.T) {
+	w, path := newWriter(t, 10, 3)
+	write(t, w, "aaaa\n", "bbbb\n") // Exactly 10 bytes: no rotation yet
+	if exists(path + ".1") {
+		t.Fatal("rotated before the file exceeded maxBytes")
+	}
+	write(t, w, "cccc\n")
+	if got := content(t, path+".1"); got != "aaaa\nbbbb\n" {
+		t.Errorf("app.log.1 = %q", got)
+	}
+	if got := content(t, path); got != "cccc\n" {
+		t.Errorf("app.log = %q", got)
+	}
+}
+
+func TestKeepsMaxBackups(t *testing.T) {
+	w, path := newWriter(t, 5, 2)
+	write(t, w, "1111\n", "2222\n", "3333\n", "4444\n")
+
+	want := map[string]string{path: "4444\n", path + ".1": "3333\n", path + ".2": "2222\n"}
+	for p, c := range want {
+		if got := content(t, p); got != c {
+			t.Errorf("%s = %q, want %q", filepath.Base(p), got, c)
+		}
+	}
+	if exists(path + ".3") {
+		t.Error("kept more than maxBackups files")
+	}
+}
+
+func TestNoBackups(t *testing.T) {
+	w, path := newWriter(t, 5, 0)
+	write(t, w, "1111\n", "2222\n")
+	if got := content(t, path); got != "2222\n" {
+		t.Errorf("app.log = %q", got)
+	}
+	if exists(path + ".1") {
+		t.Error("rotated file kept with maxBackups 0")
+	}
+}
+
+func TestRecordLargerThanMaxBytes(t *testing.T) {
+	w, path := newWriter(t, 5, 1)
+	big := strings.Repeat("x", 20) + "\n"
+	write(t, w, big) // Written whole into the empty file
+	if got := content(t, path); got != big {
+		t.Errorf("app.log = %q", got)
+	}
+	write(t, w, "next\n")
+	if got := content(t, path+".1"); got != big {
+		t.Errorf("app.log.1 = %q", got)
+	}
+	if got := content(t, path); got != "next\n" {
+		t.Errorf("app.log = %q", got)
+	}
+}
+
+func TestAppendsToExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewRotatingWriter(path, 8, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	write(t, w, "new\n") // 8 bytes with the existing content
+	write(t, w, "more\n")
+	if got := content(t, path+".1"); got != "old\nnew\n" {
+		t.Errorf("app.log.1 = %q", got)
+	}
+}
+
+func TestWriteAfterClose(t *testing.T) {
+	w, _ := newWriter(t, 0, 0)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("x\n")); err != os.ErrClosed {
+		t.Errorf("Write after Close = %v, want os.ErrClosed", err)
+	}
+}
+
+func TestConcurrentWrites(t *testing.T) {
+	const goroutines, lines = 8, 200
+	record := "0123456789abcdef\n"
+	w, path := newWriter(t, 1024, 1000)
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < lines; i++ {
+				if _, err := w.Write([]byte(record)); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	files, err := filepath.Glob(path + "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var all bytes.Buffer
+	for _, f := range files {
+		c := content(t, f)
+		if int64(len(c)) > 1024 {
+			t.Errorf("%s has %d bytes, more than maxBytes", filepath.Base(f), len(c))
+		}
+		all.WriteString(c)
+	}
+	if got := strings.Count(all.String(), record); got != goroutines*lines {
+		t.Errorf("found %d whole records, want %d", got, goroutines*lines)
+	}
+	if all.Len() != goroutines*lines*len(record) {
+		t.Errorf("wrote %d bytes, want %d", all.Len(), goroutines*lines*len(record))
+	}
+}
@@ -0,0 +1,97 @@
+package glogi
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// recordRouter picks a destination per record (nil: no routing)
+var recordRouter atomic.Pointer[router]
+
+// maxRoutedWriters caps the destinations a router keeps a writerBox for, so a
+// router returning per-request writers doesn't grow memory without bound
+const maxRoutedWriters = 64
+
+// router is a SetRouter function with the writerBoxes of the destinations it
+// returned, so the terminal check runs once per writer rather than per record
+// and writes to one destination share a lock. The boxes go with the router
+// when it is replaced.
+type router struct {
+	fn       func(r slog.Record) io.Writer
+	mu       sync.Mutex
+	boxes    map[io.Writer]*writerBox
+	overflow sync.Mutex // Serializes writes to destinations beyond the cap
+}
+
+// SetRouter sets a function that picks the destination of each record in the
+// text, logfmt and JSON handlers; returning nil writes it to the handler's output
+// as usual. Routed records are written synchronously, bypass SetAsync and
+// SetSplitOutput, and are colored when the chosen writer is a terminal (as
+// configured). Extra outputs (AddOutput) still get every record. fn is called
+// concurrently from every goroutine that logs; nil removes the router. The
+// router remembers up to 64 destinations and nothing past its replacement.
+//
+//	audit, _ := os.OpenFile("audit.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+//	log.SetRouter(func(r slog.Record) io.Writer {
+//	    if r.Level == auditLevel {
+//	        return audit
+//	    }
+//	    return nil
+//	})
+func SetRouter(fn func(r slog.Record) io.Writer) {
+	if fn == nil {
+		recordRouter.Store(nil)
+		return
+	}
+	recordRouter.Store(&router{fn: fn, boxes: map[io.Writer]*writerBox{}})
+}
+
+// routeRecord returns the writerBox of the destination the router picks for
+// r, or nil (always nil for Format, which renders into its own buffer)
+func routeRecord(ctx context.Context, r slog.Record) *writerBox {
+	rt := recordRouter.Load()
+	if rt == nil || previewing(ctx) {
+		return nil
+	}
+	w := rt.fn(r)
+	if w == nil {
+		return nil
+	}
+	return rt.box(w)
+}
+
+// box returns the writerBox of a routed destination, cached for the first
+// maxRoutedWriters comparable writers
+func (rt *router) box(w io.Writer) *writerBox {
+	if !reflect.TypeOf(w).Comparable() {
+		return &writerBox{w: w, tty: isTerminal(w), mu: &rt.overflow}
+	}
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if box, ok := rt.boxes[w]; ok {
+		return box
+	}
+	if len(rt.boxes) >= maxRoutedWriters {
+		mu := writeLock(w)
+		if mu != &stdoutLock && mu != &stderrLock {
+			mu = &rt.overflow
+		}
+		return &writerBox{w: w, tty: isTerminal(w), mu: mu}
+	}
+	box := &writerBox{w: w, tty: isTerminal(w), mu: writeLock(w)}
+	rt.boxes[w] = box
+	return box
+}
+
+// writeRouted writes a record of level l to a routed destination under its lock
+func writeRouted(box *writerBox, l slog.Level, p []byte) (int, error) {
+	n, err := box.write(l, p)
+	if err != nil {
+		reportWriteError(err)
+	}
+	return n, err
+}
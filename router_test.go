@@ -0,0 +1,52 @@
+package glogi
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRouterWritesToChosenDestination(t *testing.T) {
+	buf := captureOutput(t, "text")
+	var audit bytes.Buffer
+	SetRouter(func(r slog.Record) io.Writer {
+		if strings.HasPrefix(r.Message, "audit") {
+			return &audit
+		}
+		return nil
+	})
+	t.Cleanup(func() { SetRouter(nil) })
+
+	Info("audit: user deleted")
+	Info("request served")
+	if !strings.Contains(audit.String(), "audit: user deleted") || strings.Contains(audit.String(), "request served") {
+		t.Errorf("routed output = %q", audit.String())
+	}
+	if !strings.Contains(buf.String(), "request served") || strings.Contains(buf.String(), "audit") {
+		t.Errorf("main output = %q", buf.String())
+	}
+}
+
+func TestRouterCacheIsBounded(t *testing.T) {
+	captureOutput(t, "text")
+	var w io.Writer
+	SetRouter(func(slog.Record) io.Writer { return w })
+	t.Cleanup(func() { SetRouter(nil) })
+
+	bufs := make([]*bytes.Buffer, 2*maxRoutedWriters)
+	for i := range bufs {
+		bufs[i] = &bytes.Buffer{}
+		w = bufs[i]
+		Info("per-request writer")
+	}
+	for i, b := range bufs {
+		if !strings.Contains(b.String(), "per-request writer") {
+			t.Fatalf("writer %d got %q", i, b.String())
+		}
+	}
+	if n := len(recordRouter.Load().boxes); n > maxRoutedWriters {
+		t.Errorf("router caches %d writers, want at most %d", n, maxRoutedWriters)
+	}
+}
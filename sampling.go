@@ -0,0 +1,218 @@
+package glogi
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// dedupKeyAttrs lists attribute keys whose values are part of a record's identity
+// for dedup, sampling and rate limiting (in addition to level and message)
+var dedupKeyAttrs []string
+
+// SetDedupKeyAttrs sets which attribute values, besides level and message,
+// identify a record for dedup/sampling/rate limiting. For example with
+// SetDedupKeyAttrs("error_code") two "request failed" records with different
+// error codes are tracked separately. Call with no keys to reset.
+func SetDedupKeyAttrs(keys ...string) {
+	dedupKeyAttrs = append([]string(nil), keys...)
+}
+
+// recordKey builds the identity of a record: level, message and the values of
+// the configured dedup key attrs (looked up in record attrs, then handler attrs)
+func recordKey(r slog.Record, handlerAttrs []slog.Attr) string {
+	var b strings.Builder
+	b.WriteString(r.Level.String())
+	b.WriteByte(0)
+	b.WriteString(r.Message)
+
+	for _, key := range dedupKeyAttrs {
+		var val string
+		found := false
+		r.Attrs(func(a slog.Attr) bool {
+			if a.Key == key {
+				val, found = a.Value.String(), true
+				return false
+			}
+			return true
+		})
+		if !found {
+			for _, a := range handlerAttrs {
+				if a.Key == key {
+					val, found = a.Value.String(), true
+					break
+				}
+			}
+		}
+		b.WriteByte(0)
+		if found {
+			b.WriteString(key)
+			b.WriteByte('=')
+			b.WriteString(val)
+		}
+	}
+	return b.String()
+}
+
+// maxSampleKeys bounds the sampler's counter map; it is reset when full so
+// high-cardinality messages can't grow memory without limit
+const maxSampleKeys = 10000
+
+var (
+	sampling     atomic.Pointer[samplingPolicy] // nil: sampling disabled
+	sampleMu     sync.Mutex
+	sampleCounts map[string]*sampleCount
+)
+
+// samplingPolicy is the configuration set by SetSampling or SetBurstSampling
+type samplingPolicy struct {
+	level  slog.Level    // Records at or below this level are sampled
+	first  uint64        // Emit the first N identical records of a window
+	every  uint64        // Then emit every Nth (0: none)
+	window time.Duration // Counts restart after this long (0: never)
+	errors bool          // Sample ERROR records too
+}
+
+// burstWindow is how often SetBurstSampling restarts its counts
+const burstWindow = time.Second
+
+// sampleCount tracks one record identity
+type sampleCount struct {
+	seen    uint64    // Seen in the current window
+	start   time.Time // Start of the current window
+	dropped uint64    // Dropped since the last emitted record
+}
+
+// SetSampling emits only every Nth identical record at or below level; the
+// others are dropped and counted. The next emitted record carries a
+// sampled_dropped=K attr with the number dropped since the previous one.
+// Records are identical when level and message match (plus the attrs set with
+// SetDedupKeyAttrs). ERROR and above are never sampled. everyN <= 1 disables
+// sampling.
+//
+//	log.SetSampling(log.LevelInfo, 100) // 1 in 100 repeated DEBUG/INFO lines
+func SetSampling(level slog.Level, everyN int) {
+	sampleMu.Lock()
+	defer sampleMu.Unlock()
+	sampleCounts = make(map[string]*sampleCount)
+	if everyN <= 1 {
+		sampling.Store(nil)
+		return
+	}
+	sampling.Store(&samplingPolicy{level: level, first: 1, every: uint64(everyN)})
+}
+
+// SetBurstSampling emits the first identical records at or below level in
+// each second, then only every thereafterEveryN-th one (none if it is 0), so
+// the first occurrences of a new event always show up while a storm of them
+// is thinned out. Unlike SetSampling it also applies to ERROR; FATAL and PANIC
+// are never sampled. Dropped records are counted in sampled_dropped as with
+// SetSampling, which it replaces (and vice versa).
+//
+//	log.SetBurstSampling(log.LevelError, 10, 100) // Per second: 10, then 1 in 100
+func SetBurstSampling(level slog.Level, first, thereafterEveryN int) {
+	sampleMu.Lock()
+	defer sampleMu.Unlock()
+	sampleCounts = make(map[string]*sampleCount)
+	sampling.Store(&samplingPolicy{
+		level:  level,
+		first:  uint64(max(first, 0)),
+		every:  uint64(max(thereafterEveryN, 0)),
+		window: burstWindow,
+		errors: true,
+	})
+}
+
+// sampleRecord reports whether r should be emitted under the sampling policy.
+// An emitted record gets a sampled_dropped attr if identical records were
+// dropped before it. handlerAttrs supplies the handler-level attrs for
+// SetDedupKeyAttrs lookups and is only called when dedup keys are set.
+func sampleRecord(r *slog.Record, handlerAttrs func() []slog.Attr) bool {
+	p := sampling.Load()
+	if p == nil || r.Level > p.level || r.Level >= LevelFatal || (r.Level >= LevelError && !p.errors) {
+		return true
+	}
+	var attrs []slog.Attr
+	if len(dedupKeyAttrs) > 0 {
+		attrs = handlerAttrs()
+	}
+	key := recordKey(*r, attrs)
+
+	sampleMu.Lock()
+	c := sampleCounts[key]
+	if c == nil {
+		if len(sampleCounts) >= maxSampleKeys {
+			sampleCounts = make(map[string]*sampleCount)
+		}
+		c = &sampleCount{}
+		sampleCounts[key] = c
+	}
+	if p.window > 0 {
+		if now := clock(); now.Sub(c.start) >= p.window {
+			c.start, c.seen = now, 0
+		}
+	}
+	c.seen++
+	if c.seen > p.first && (p.every == 0 || (c.seen-p.first)%p.every != 0) {
+		c.dropped++
+		sampleMu.Unlock()
+		return false
+	}
+	dropped := c.dropped
+	c.dropped = 0
+	sampleMu.Unlock()
+
+	if dropped > 0 {
+		*r = r.Clone()
+		r.AddAttrs(slog.Uint64("sampled_dropped", dropped))
+	}
+	return true
+}
+
+// admitRecord applies the filter, masks secrets (SetSecretScrubbing), applies
+// the volume controls (message rate limits, error collapsing, then sampling)
+// and reports whether r should be emitted. Emitted records are counted in
+// Stats and numbered when SetSequenceNumbers is on. Records rendered by Format
+// only go through the filters and secret masking. A record sent to several
+// handlers (AddHandler) is admitted once, by the first one.
+func admitRecord(ctx context.Context, r *slog.Record, handlerAttrs func() []slog.Attr) bool {
+	d := fanoutAdmit(ctx)
+	if d == nil {
+		return admitOnce(ctx, r, handlerAttrs)
+	}
+	if !d.done {
+		d.done, d.admit = true, admitOnce(ctx, r, handlerAttrs)
+		d.r = r.Clone()
+	} else if d.admit {
+		*r = d.r.Clone()
+	}
+	return d.admit
+}
+
+// admitOnce implements admitRecord for one handler
+func admitOnce(ctx context.Context, r *slog.Record, handlerAttrs func() []slog.Attr) bool {
+	if recordFilter != nil && !recordFilter(*r) {
+		return false
+	}
+	if !subsystemAdmits(*r) {
+		return false
+	}
+	scrubRecord(r)
+	if previewing(ctx) {
+		return true // Format: no rate limits, sampling, stats or sequence numbers
+	}
+	if !rateLimitRecord(r) || !collapseRecord(ctx, r, handlerAttrs) || !sampleRecord(r, handlerAttrs) {
+		return false
+	}
+	countRecord(r.Level)
+	if sequenceNumbers {
+		addSequence(r)
+	}
+	if includeGoroutineID {
+		addGoroutineID(r) // Handle runs on the logging goroutine, even in async mode
+	}
+	return true
+}
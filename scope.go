@@ -0,0 +1,77 @@
+package glogi
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// scopeIndent is the text output's indentation per Group level
+const scopeIndent = "  "
+
+// indentHandler is a handler that can indent the messages of a Group
+type indentHandler interface {
+	withIndent() slog.Handler
+}
+
+// ScopedLogger logs the steps of one operation, started with Group. Its
+// records carry a group=name attr and, in the text output, are indented one
+// level deeper than the logger it came from. End logs a summary line.
+type ScopedLogger struct {
+	*Logger
+	parent *Logger // Logs the summary, at the outer indentation
+	base   *Logger // Indented, without the group attr (for nested groups)
+	path   string  // Group names from the outermost, joined with "/"
+	start  time.Time
+}
+
+// Group starts a scope for a multi-step operation on the global logger:
+//
+//	g := log.Group("checkout")
+//	g.Info("reserve stock")      //   reserve stock group=checkout
+//	pay := g.Group("payment")
+//	pay.Info("charge card")      //     charge card group=checkout/payment
+//	pay.End()                    //   checkout/payment done elapsed=... group=checkout
+//	g.End()                      // checkout done elapsed=...
+//
+// Indentation is a text-output feature; other formats get the group attr only.
+func Group(name string) *ScopedLogger {
+	return (*Logger)(nil).Group(name)
+}
+
+// Group starts a scope whose records are indented one level deeper than l's.
+// On a nil Logger it uses the global logger.
+func (l *Logger) Group(name string) *ScopedLogger {
+	if l == nil {
+		ensureInit()
+		l = defaultLogger()
+	}
+	return newScope(l, l, name)
+}
+
+// Group starts a nested scope, indented one level deeper than s
+func (s *ScopedLogger) Group(name string) *ScopedLogger {
+	return newScope(s.Logger, s.base, s.path+"/"+name)
+}
+
+// newScope creates a scope below parent; base is the parent's logger without
+// its group attr
+func newScope(parent, base *Logger, path string) *ScopedLogger {
+	h := base.slogger().Handler()
+	if ih, ok := h.(indentHandler); ok {
+		h = ih.withIndent()
+	}
+	indented := &Logger{sl: slog.New(h), level: base.level, worker: base.worker}
+	return &ScopedLogger{
+		Logger: indented.With("group", path),
+		parent: parent,
+		base:   indented,
+		path:   path,
+		start:  time.Now(),
+	}
+}
+
+// End logs "<group> done" with the time since Group at the outer indentation
+func (s *ScopedLogger) End() {
+	s.parent.log(context.Background(), 0, LevelInfo, s.path+" done", "elapsed", time.Since(s.start))
+}
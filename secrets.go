@@ -0,0 +1,123 @@
+package glogi
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"regexp"
+)
+
+// defaultSecretPatterns match common credentials wherever they appear
+var defaultSecretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),                          // AWS access key ID
+	regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`), // JWT
+	regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*`),                   // Authorization: Bearer <token>
+	regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`),                         // GitHub token
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`),                     // PEM private key header
+}
+
+var (
+	secretScrubbing bool // Scan records for secrets (LOG_SECRET_SCRUB)
+	secretPatterns  = append([]*regexp.Regexp(nil), defaultSecretPatterns...)
+	secretMinLevel  = slog.Level(math.MinInt) // Lowest level scanned
+)
+
+// SetSecretScrubbing scans the message and attr values of every record for
+// secrets, whatever the attr key, and replaces each match with
+// "***REDACTED***". The default patterns match AWS access key IDs, JWTs,
+// "Bearer <token>", GitHub tokens and PEM private keys; AddSecretPattern adds
+// more. String, error and fmt.Stringer values are checked, inside groups and
+// LogValuers too. With attrs are fixed at setup and not scanned: use
+// SetRedactKeys for them. Regexes cost time on every record, so it is off by
+// default (LOG_SECRET_SCRUB=1 turns it on); SetSecretScrubLevels limits it.
+func SetSecretScrubbing(enabled bool) { secretScrubbing = enabled }
+
+// AddSecretPattern adds a regex to the secret patterns of SetSecretScrubbing.
+// Call it at startup, before logging.
+//
+//	log.AddSecretPattern(regexp.MustCompile(`\bsk_live_[0-9a-zA-Z]{24}\b`)) // Stripe key
+func AddSecretPattern(re *regexp.Regexp) {
+	if re != nil {
+		secretPatterns = append(secretPatterns, re)
+	}
+}
+
+// SetSecretScrubLevels scans only records at min and above for secrets, e.g.
+// LevelInfo to skip the cost on hot DEBUG paths. All levels are scanned by default.
+func SetSecretScrubLevels(min slog.Level) { secretMinLevel = min }
+
+// scrubRecord masks secrets in r's message and attrs when scrubbing applies
+func scrubRecord(r *slog.Record) {
+	if !secretScrubbing || r.Level < secretMinLevel {
+		return
+	}
+	msg, changed := scrubString(r.Message)
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		a, ok := scrubAttr(a, 0)
+		changed = changed || ok
+		attrs = append(attrs, a)
+		return true
+	})
+	if !changed {
+		return
+	}
+	scrubbed := slog.NewRecord(r.Time, r.Level, msg, r.PC)
+	scrubbed.AddAttrs(attrs...)
+	*r = scrubbed
+}
+
+// scrubAttr masks secrets in a's value and reports whether it changed
+func scrubAttr(a slog.Attr, depth int) (slog.Attr, bool) {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		if s, ok := scrubString(v.String()); ok {
+			return slog.String(a.Key, s), true
+		}
+	case slog.KindGroup:
+		if depth >= maxAttrDepth {
+			break
+		}
+		group := v.Group()
+		out := make([]slog.Attr, len(group))
+		changed := false
+		for i, ga := range group {
+			var ok bool
+			out[i], ok = scrubAttr(ga, depth+1)
+			changed = changed || ok
+		}
+		if changed {
+			return slog.Attr{Key: a.Key, Value: slog.GroupValue(out...)}, true
+		}
+	case slog.KindAny:
+		if isNilValue(v.Any()) {
+			break // A typed nil would panic in Error or String
+		}
+		var s string
+		switch x := v.Any().(type) {
+		case error:
+			s = x.Error()
+		case fmt.Stringer:
+			s = x.String()
+		default:
+			return a, false
+		}
+		if s, ok := scrubString(s); ok {
+			return slog.String(a.Key, s), true
+		}
+	}
+	return a, false
+}
+
+// scrubString replaces the secret pattern matches in s and reports whether any matched
+func scrubString(s string) (string, bool) {
+	changed := false
+	for _, re := range secretPatterns {
+		if re.MatchString(s) {
+			s = re.ReplaceAllLiteralString(s, redactedValue)
+			changed = true
+		}
+	}
+	return s, changed
+}
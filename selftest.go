@@ -0,0 +1,156 @@
+package glogi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"runtime"
+)
+
+// configSnapshot returns the active configuration as ordered attrs
+func configSnapshot() []slog.Attr {
+	ensureInit()
+	format := "text"
+	colors := false
+	if h, ok := primaryHandler().(*ColoredHandler); ok {
+		colors = h.colorsOn()
+		if h.logfmt {
+			format = "logfmt"
+		}
+	}
+	if h, ok := primaryHandler().(*JSONHandler); ok {
+		format = "json"
+		if h.ecs {
+			format = "ecs"
+		} else if h.gcp {
+			format = "gcp"
+		}
+	}
+	return []slog.Attr{
+		slog.String("level", levelName(level.Level())),
+		slog.String("format", format),
+		slog.Bool("compact", compact),
+		slog.Bool("colors", colors),
+		slog.Int("color_depth", int(colorDepth)),
+		slog.Bool("source", includeSource),
+		slog.Int("source_width", sourceWidth),
+		slog.String("source_mode", string(sourceMode)),
+		slog.Bool("source_func", showFunction),
+		slog.Bool("custom_source_formatter", sourceFormatter != nil),
+		slog.String("time_format", fmt.Sprintf("%q", timeFormat)),
+		slog.Bool("time_utc", timeUTC),
+		slog.Bool("relative_time", relativeTime),
+		slog.Bool("sort_attrs", sortAttrs),
+		slog.String("attrs_position", string(attrsPosition)),
+		slog.Int("max_attr_value_len", maxAttrValueLen),
+		slog.Bool("secret_scrubbing", secretScrubbing),
+		slog.String("line_terminator", fmt.Sprintf("%q", lineTerminator)),
+		slog.String("group_style", string(groupStyle)),
+		slog.Bool("log_deadline", logDeadline),
+		slog.Bool("async", asyncEnabled()),
+		slog.Bool("split_output", splitOutput),
+		slog.String("color_trace", fmt.Sprintf("%q", colorTrace)),
+		slog.String("color_debug", fmt.Sprintf("%q", colorDebug)),
+		slog.String("color_info", fmt.Sprintf("%q", colorInfo)),
+		slog.String("color_warn", fmt.Sprintf("%q", colorWarn)),
+		slog.String("color_error", fmt.Sprintf("%q", colorError)),
+		slog.Bool("custom_color_func", colorFunc != nil),
+		slog.String("color_source", fmt.Sprintf("%q", colorSource)),
+		slog.String("color_min_level", levelName(colorMinLevel)),
+	}
+}
+
+// configBanner logs the effective configuration when the logger is built
+// (LOG_CONFIG_BANNER)
+var configBanner bool
+
+// SetLogConfigOnInit makes Init (and InitWith) log one INFO line with the
+// effective configuration, whatever the level: level, format, whether colors
+// are on (after terminal detection), source width, time format and output.
+// It answers "why aren't my debug logs showing" without a support round trip.
+// Set it before Init. Off by default.
+//
+//	[2025/12/26 15:04:05] INFO  [glogi               ] glogi config min_level=WARN format=text colors=false source_width=20 time_format="2006/01/02 15:04:05" output=stdout
+func SetLogConfigOnInit(enabled bool) { configBanner = enabled }
+
+// logConfigBanner writes the configuration line for a logger writing to out
+func logConfigBanner(out io.Writer) {
+	format := "text"
+	colors := false
+	switch h := primaryHandler().(type) {
+	case *ColoredHandler:
+		colors = h.colorsOn()
+		if h.logfmt {
+			format = "logfmt"
+		}
+	case *JSONHandler:
+		format = "json"
+		if h.ecs {
+			format = "ecs"
+		} else if h.gcp {
+			format = "gcp"
+		}
+	}
+	r := slog.NewRecord(clock(), LevelInfo, "glogi config", 0)
+	r.AddAttrs(
+		slog.String(sourceAttrKey, "glogi"),
+		slog.String("min_level", levelName(level.Level())),
+		slog.String("format", format),
+		slog.Bool("colors", colors),
+		slog.Int("source_width", sourceWidth),
+		slog.String("time_format", timeFormat),
+		slog.String("output", describeOutput(out)),
+	)
+	_ = primaryHandler().Handle(context.Background(), r)
+}
+
+// describeOutput names a destination for the config banner
+func describeOutput(w io.Writer) string {
+	switch {
+	case w == os.Stdout:
+		return "stdout"
+	case w == os.Stderr:
+		return "stderr"
+	case ownedOutput != nil && w == ownedOutput.(io.Writer):
+		return os.Getenv("LOG_FILE")
+	}
+	if f, ok := w.(*os.File); ok {
+		return f.Name()
+	}
+	return fmt.Sprintf("%T", w)
+}
+
+// SelfTest writes a diagnostic report to w: the active configuration followed
+// by one sample record per level (TRACE through PANIC) formatted exactly like
+// real output. Samples are written regardless of the level threshold, and
+// FATAL/PANIC samples neither exit nor panic. Useful for support tickets
+// ("colors not showing", "level filtering wrong").
+func SelfTest(w io.Writer) error {
+	ensureInit()
+	fmt.Fprintln(w, "glogi configuration:")
+	for _, a := range configSnapshot() {
+		fmt.Fprintf(w, "  %-24s %s\n", a.Key, a.Value.String())
+	}
+	fmt.Fprintln(w, "glogi sample output:")
+
+	h, ok := primaryHandler().(writerHandler)
+	if !ok {
+		_, err := fmt.Fprintln(w, "  (custom handler, samples unavailable)")
+		return err
+	}
+	sample := h.withWriter(w)
+
+	var pcs [1]uintptr
+	runtime.Callers(2, pcs[:]) // skip: Callers, SelfTest
+
+	for _, lvl := range []slog.Level{LevelTrace, LevelDebug, LevelInfo, LevelWarn, LevelError, LevelFatal, LevelPanic} {
+		r := slog.NewRecord(clock(), lvl, "sample "+levelName(lvl)+" message", pcs[0])
+		r.AddAttrs(slog.String("key", "value"), slog.Int("n", 42))
+		if err := sample.Handle(context.Background(), r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
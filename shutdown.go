@@ -0,0 +1,178 @@
+package glogi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Flush drains buffered output: it waits for records queued by the async
+// writer (SetAsync), then flushes the destination if it buffers (a Flush
+// method, like *bufio.Writer) or syncs it to disk (a Sync method, like
+// *os.File or rotate.RotatingWriter). Fatal and PanicLog call it before exiting.
+func Flush() error {
+	var errs []error
+	asyncMu.Lock()
+	a := asyncOut
+	asyncMu.Unlock()
+	if a != nil {
+		errs = append(errs, a.flush())
+	}
+	return errors.Join(append(errs, syncOutputs()...)...)
+}
+
+// Close writes pending SetErrorCollapse summaries, flushes the output, stops background goroutines (the async writer)
+// and closes the log files opened for LOG_FILE and SetLogDir. Call it before
+// the process exits, once other goroutines have stopped logging. Logging after
+// Close reinitializes the global logger lazily (from env, like the first call).
+func Close() error {
+	initMu.Lock()
+	defer initMu.Unlock()
+	if !initDone.Load() {
+		return nil
+	}
+
+	flushCollapsed()
+	var errs []error
+	asyncMu.Lock()
+	if asyncOut != nil {
+		errs = append(errs, asyncOut.close())
+		if h, ok := primaryHandler().(writerHandler); ok {
+			h.setOutput(asyncOut.out)
+		}
+		asyncOut = nil
+	}
+	asyncMu.Unlock()
+
+	errs = append(errs, syncOutputs()...)
+	if ownedOutput != nil {
+		errs = append(errs, ownedOutput.Close())
+		ownedOutput = nil
+	}
+	errs = append(errs, closeLogDir()...)
+	initDone.Store(false)
+	return errors.Join(errs...)
+}
+
+// syncOutputs flushes or syncs the global logger's destinations
+func syncOutputs() []error {
+	if !initDone.Load() {
+		return nil
+	}
+	h, ok := primaryHandler().(writerHandler)
+	if !ok {
+		return nil
+	}
+	var errs []error
+	out := h.output()
+	if ref, ok := out.(*outputRef); ok {
+		out = ref.Load()
+		if tees := ref.tees.Load(); tees != nil {
+			for _, tee := range *tees {
+				errs = append(errs, syncWriter(tee.box.w))
+			}
+		}
+	}
+	if a, ok := out.(*asyncWriter); ok {
+		out = a.out
+	}
+	errs = append(errs, syncWriter(out))
+	if splitOutput {
+		errs = append(errs, syncWriter(errorOutput))
+	}
+	return errs
+}
+
+// syncWriter flushes w if it buffers, or syncs it if it is backed by a file.
+// Stdout and stderr are unbuffered and usually not syncable (pipes, terminals),
+// so they are skipped.
+func syncWriter(w io.Writer) error {
+	if w == os.Stdout || w == os.Stderr {
+		return nil
+	}
+	switch f := w.(type) {
+	case interface{ Flush() error }:
+		return f.Flush()
+	case interface{ Flush() }:
+		f.Flush()
+	case interface{ Sync() error }:
+		return f.Sync()
+	}
+	return nil
+}
+
+var (
+	exitMu       sync.Mutex
+	exitHandlers []func()
+)
+
+// RegisterExitHandler registers fn to run when Fatal (or Fatalf/Fatalln)
+// exits the process, e.g. to close database connections; deferred functions
+// don't run on os.Exit. Handlers run in reverse registration order (LIFO, like
+// defer); a panicking handler is reported on stderr and the rest still run.
+// Logging output is flushed after the handlers. A custom SetFatalBehavior
+// replaces the handlers and the exit.
+func RegisterExitHandler(fn func()) {
+	exitMu.Lock()
+	defer exitMu.Unlock()
+	exitHandlers = append(exitHandlers, fn)
+}
+
+// runExitHandlers runs the registered exit handlers, last registered first
+func runExitHandlers() {
+	exitMu.Lock()
+	handlers := append([]func(){}, exitHandlers...)
+	exitMu.Unlock()
+	for i := len(handlers) - 1; i >= 0; i-- {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					fmt.Fprintf(os.Stderr, "glogi: exit handler panicked: %v\n", r)
+				}
+			}()
+			handlers[i]()
+		}()
+	}
+}
+
+// Fatal and panic behavior (nil: os.Exit(1) and panic(msg))
+var (
+	fatalBehavior func()
+	panicBehavior func(msg string)
+)
+
+// SetFatalBehavior replaces what Fatal (and Fatalf, Fatalln, RecoverAndExit)
+// do after logging and flushing, instead of running the exit handlers and
+// calling os.Exit(1). Libraries can use it to keep a Fatal from killing the
+// host process. If fn returns, the Fatal call returns too. nil restores the default.
+func SetFatalBehavior(fn func()) { fatalBehavior = fn }
+
+// SetPanicBehavior replaces what PanicLog (and Panic, Panicf, Panicln) do
+// after logging and flushing, instead of panic(msg). If fn returns, the call
+// returns too. nil restores the default.
+func SetPanicBehavior(fn func(msg string)) { panicBehavior = fn }
+
+// exit flushes the output and runs the fatal behavior: by default the exit
+// handlers, then os.Exit(1)
+func exit() {
+	if fn := fatalBehavior; fn != nil {
+		_ = Flush()
+		fn()
+		return
+	}
+	runExitHandlers()
+	_ = Flush()
+	os.Exit(1)
+}
+
+// panicAfterLog flushes the output and runs the panic behavior, by default panic(msg)
+func panicAfterLog(msg string) {
+	_ = Flush()
+	if fn := panicBehavior; fn != nil {
+		fn(msg)
+		return
+	}
+	panic(msg)
+}
@@ -0,0 +1,57 @@
+package glogi
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// InstallSignalHandler toggles DEBUG logging each time the process receives
+// sig (e.g. syscall.SIGHUP): the first signal lowers the global level to DEBUG,
+// the next restores the level that was active before. If the level is already
+// DEBUG or lower when the signal arrives, it is raised to the level set by
+// LOG_LEVEL instead (INFO if that is unset or itself DEBUG or lower).
+//
+// It starts one goroutine that waits for the signal; the returned stop
+// function unregisters the signal and ends the goroutine. Nothing is
+// installed unless this is called.
+//
+//	stop := log.InstallSignalHandler(syscall.SIGHUP) // kill -HUP <pid> toggles DEBUG
+//	defer stop()
+func InstallSignalHandler(sig os.Signal) (stop func()) {
+	ensureInit()
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, sig)
+
+	go func() {
+		saved := parseLevel(os.Getenv("LOG_LEVEL"))
+		if saved <= LevelDebug {
+			saved = LevelInfo
+		}
+		for {
+			select {
+			case <-ch:
+				current := GetLevel()
+				if current > LevelDebug {
+					saved = current
+					SetLevelValue(LevelDebug)
+					Info("debug logging enabled by signal", "signal", sig.String())
+				} else {
+					SetLevelValue(saved)
+					Info("debug logging disabled by signal", "signal", sig.String(), "level", levelName(saved))
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
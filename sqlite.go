@@ -0,0 +1,345 @@
+//go:build glogi_sqlite
+
+package glogi
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SQLite persistence is optional and compiled only with the glogi_sqlite build tag:
+//
+//	go build -tags glogi_sqlite
+//
+// glogi does not import a driver itself. The application must register one,
+// e.g. `import _ "github.com/mattn/go-sqlite3"` (driver name "sqlite3", the default)
+// or `import _ "modernc.org/sqlite"` together with `glogi.SQLiteDriver = "sqlite"`.
+
+// SQLiteDriver is the database/sql driver name used by NewSQLiteHandler
+var SQLiteDriver = "sqlite3"
+
+// SQLite batching settings
+const (
+	sqliteBatchSize     = 100         // Flush when this many records are pending
+	sqliteFlushInterval = time.Second // Flush pending records at least this often
+	sqliteMaxPending    = 10000       // Records kept while the database fails; older ones are dropped
+)
+
+const sqliteSchema = `CREATE TABLE IF NOT EXISTS logs (
+	id        INTEGER PRIMARY KEY AUTOINCREMENT,
+	time      TIMESTAMP NOT NULL,
+	level     TEXT NOT NULL,
+	level_num INTEGER NOT NULL,
+	source    TEXT NOT NULL,
+	msg       TEXT NOT NULL,
+	attrs     TEXT NOT NULL
+)`
+
+// sqliteRow is a single pending insert
+type sqliteRow struct {
+	time   time.Time
+	level  slog.Level
+	source string
+	msg    string
+	attrs  string
+}
+
+// sqliteStore is shared by a handler and all handlers derived from it
+// via WithAttrs/WithGroup. It owns the database and the pending batch.
+type sqliteStore struct {
+	db      *sql.DB
+	mu      sync.Mutex
+	pending []sqliteRow
+	dropped atomic.Uint64 // Records dropped because pending was full
+	stop    chan struct{}
+	done    chan struct{}
+
+	closeOnce sync.Once
+}
+
+// SQLiteHandler implements slog.Handler and inserts each record into a "logs" table.
+// Inserts are batched: records are written when the batch fills up, once per
+// sqliteFlushInterval, and on Flush/Close.
+type SQLiteHandler struct {
+	level  *slog.LevelVar
+	store  *sqliteStore
+	attrs  []groupedAttr
+	groups []string
+}
+
+// NewSQLiteHandler opens (or creates) the database at path and prepares the logs table.
+// The handler shares the global level, so SetLevel applies to it as well.
+// Use it with slog.New(h) or alongside the default handler.
+func NewSQLiteHandler(path string) (*SQLiteHandler, error) {
+	db, err := sql.Open(SQLiteDriver, path)
+	if err != nil {
+		return nil, fmt.Errorf("glogi: open sqlite: %w", err)
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("glogi: create logs table: %w", err)
+	}
+
+	store := &sqliteStore{
+		db:   db,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go store.loop()
+
+	ensureInit()
+	return &SQLiteHandler{level: level, store: store}, nil
+}
+
+func (h *SQLiteHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	if (silent && l < LevelFatal) || skipCancelled(ctx, l) {
+		return false
+	}
+	if forced, ok := forcedLevel(ctx); ok {
+		return l >= forced
+	}
+	if sub, ok := subsystemLevel(h.firstGroup(), h.handlerAttrs); ok {
+		return l >= sub
+	}
+	return l >= h.level.Level()
+}
+
+func (h *SQLiteHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !admitRecord(ctx, &r, h.handlerAttrs) {
+		return nil
+	}
+	source, _ := takeSourceOverride(&r)
+
+	attrs := make(map[string]any)
+	for _, ga := range h.attrs {
+		addJSONAttr(attrs, groupPrefix(ga.groups), ga.attr)
+	}
+	prefix := groupPrefix(h.groups)
+	r.Attrs(func(a slog.Attr) bool {
+		addJSONAttr(attrs, prefix, a)
+		return true
+	})
+	ctxAttrs := contextAttrs(ctx)
+	for _, a := range ctxAttrs {
+		addJSONAttr(attrs, "", a)
+	}
+	if hooksDue(ctx) {
+		runHooks(r, hookAttrs(r, h.groups, h.attrs, ctxAttrs))
+	}
+
+	attrsJSON := []byte("{}")
+	if len(attrs) > 0 {
+		b, err := jsonMarshal(attrs)
+		if err != nil {
+			return err
+		}
+		attrsJSON = b
+	}
+
+	return h.store.add(sqliteRow{
+		time:   r.Time,
+		level:  r.Level,
+		source: source,
+		msg:    r.Message,
+		attrs:  string(attrsJSON),
+	})
+}
+
+// handlerAttrs returns the attrs added with WithAttrs, without group paths
+func (h *SQLiteHandler) handlerAttrs() []slog.Attr {
+	attrs := make([]slog.Attr, len(h.attrs))
+	for i, ga := range h.attrs {
+		attrs[i] = ga.attr
+	}
+	return attrs
+}
+
+func (h *SQLiteHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &SQLiteHandler{
+		level:  h.level,
+		store:  h.store,
+		attrs:  appendGroupedAttrs(h.attrs, h.groups, attrs),
+		groups: h.groups,
+	}
+}
+
+func (h *SQLiteHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	groups := make([]string, 0, len(h.groups)+1)
+	groups = append(groups, h.groups...)
+	return &SQLiteHandler{
+		level:  h.level,
+		store:  h.store,
+		attrs:  h.attrs,
+		groups: append(groups, name),
+	}
+}
+
+// firstGroup returns the outermost group name ("" without groups)
+func (h *SQLiteHandler) firstGroup() string {
+	if len(h.groups) == 0 {
+		return ""
+	}
+	return h.groups[0]
+}
+
+// withLevel returns a copy of the handler with its own minimum level
+func (h *SQLiteHandler) withLevel(lv *slog.LevelVar) slog.Handler {
+	return &SQLiteHandler{level: lv, store: h.store, attrs: h.attrs, groups: h.groups}
+}
+
+// Flush writes all pending records to the database
+func (h *SQLiteHandler) Flush() error {
+	h.store.mu.Lock()
+	defer h.store.mu.Unlock()
+	return h.store.flushLocked()
+}
+
+// Close flushes pending records, stops the background flusher and closes the database
+// (only the first call does; later ones return nil)
+func (h *SQLiteHandler) Close() error {
+	var err error
+	h.store.closeOnce.Do(func() {
+		close(h.store.stop)
+		<-h.store.done
+
+		err = h.Flush()
+		if cerr := h.store.db.Close(); err == nil {
+			err = cerr
+		}
+	})
+	return err
+}
+
+// Dropped returns how many records were dropped because the database kept
+// failing while 10000 records were already waiting to be written
+func (h *SQLiteHandler) Dropped() uint64 {
+	return h.store.dropped.Load()
+}
+
+// DB returns the underlying database for custom queries
+func (h *SQLiteHandler) DB() *sql.DB {
+	return h.store.db
+}
+
+// SQLiteEntry is a stored log record returned by Query
+type SQLiteEntry struct {
+	Time   time.Time
+	Level  string
+	Source string
+	Msg    string
+	Attrs  string // JSON object
+}
+
+// Query returns up to limit most recent records at or above minLevel whose
+// message contains text literally (% and _ are not wildcards; empty text
+// matches everything). Pending records are flushed first so the result
+// includes everything logged so far.
+func (h *SQLiteHandler) Query(minLevel slog.Level, text string, limit int) ([]SQLiteEntry, error) {
+	if err := h.Flush(); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+
+	rows, err := h.store.db.Query(
+		`SELECT time, level, source, msg, attrs FROM logs
+		 WHERE level_num >= ? AND msg LIKE '%' || ? || '%' ESCAPE '\'
+		 ORDER BY id DESC LIMIT ?`,
+		int(minLevel), likeEscaper.Replace(text), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []SQLiteEntry
+	for rows.Next() {
+		var e SQLiteEntry
+		if err := rows.Scan(&e.Time, &e.Level, &e.Source, &e.Msg, &e.Attrs); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// likeEscaper escapes the LIKE wildcards in a Query search text, so % and _
+// match themselves
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// add queues a row and flushes when the batch is full
+func (s *sqliteStore) add(row sqliteRow) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.pending) >= sqliteMaxPending {
+		// The database keeps failing: drop the oldest record rather than grow without bound
+		s.pending = append(s.pending[:0], s.pending[1:]...)
+		s.dropped.Add(1)
+	}
+	s.pending = append(s.pending, row)
+	if len(s.pending) >= sqliteBatchSize {
+		return s.flushLocked()
+	}
+	return nil
+}
+
+// flushLocked inserts all pending rows in one transaction. Caller must hold s.mu.
+func (s *sqliteStore) flushLocked() error {
+	if len(s.pending) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare(`INSERT INTO logs (time, level, level_num, source, msg, attrs) VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, row := range s.pending {
+		if _, err := stmt.Exec(row.time, levelName(row.level), int(row.level), row.source, row.msg, row.attrs); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	s.pending = s.pending[:0]
+	return nil
+}
+
+// loop periodically flushes pending rows until stop is closed
+func (s *sqliteStore) loop() {
+	defer close(s.done)
+	ticker := time.NewTicker(sqliteFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.mu.Lock()
+			if err := s.flushLocked(); err != nil {
+				reportWriteError(err)
+			}
+			s.mu.Unlock()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// Ensure SQLiteHandler implements slog.Handler
+var _ slog.Handler = (*SQLiteHandler)(nil)
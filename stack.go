@@ -0,0 +1,167 @@
+package glogi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// StackFrame is a single frame of a captured stack trace
+type StackFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// Stack is a captured call stack.
+// Structured handlers encode it as an array of frames; colored output
+// renders it as text via String().
+type Stack []StackFrame
+
+// String renders the stack like runtime.Stack: function, then indented file:line
+func (s Stack) String() string {
+	var b strings.Builder
+	for i, f := range s {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "%s\n\t%s:%d", f.Function, f.File, f.Line)
+	}
+	return b.String()
+}
+
+// glogiPkgPrefix is the function name prefix of this package ("github.com/neoff/glogi.")
+var glogiPkgPrefix = func() string {
+	pc, _, _, _ := runtime.Caller(0)
+	name := runtime.FuncForPC(pc).Name()
+	slash := strings.LastIndex(name, "/")
+	if dot := strings.Index(name[slash+1:], "."); dot >= 0 {
+		return name[:slash+1+dot+1]
+	}
+	return name
+}()
+
+// captureStack returns the current call stack, skipping glogi's own frames
+func captureStack() Stack {
+	pcs := make([]uintptr, 64)
+	for {
+		n := runtime.Callers(1, pcs)
+		if n < len(pcs) {
+			pcs = pcs[:n]
+			break
+		}
+		pcs = make([]uintptr, len(pcs)*2)
+	}
+	return stackFromPCs(pcs)
+}
+
+// stackFromPCs resolves return PCs (as from runtime.Callers) into frames,
+// skipping glogi's own frames
+func stackFromPCs(pcs []uintptr) Stack {
+	var stack Stack
+	frames := runtime.CallersFrames(pcs)
+	for {
+		f, more := frames.Next()
+		if f.Function != "" && !strings.HasPrefix(f.Function, glogiPkgPrefix) {
+			stack = append(stack, StackFrame{Function: f.Function, File: f.File, Line: f.Line})
+		}
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+// errorStack returns the stack trace recorded by err, if any. The error chain
+// is walked with errors.Unwrap and the innermost trace wins, since it is the
+// closest to where the error originated. Traces are read from a StackTrace()
+// method returning a slice of PCs, as provided by github.com/pkg/errors.
+func errorStack(err error) (Stack, bool) {
+	var found Stack
+	for ; err != nil; err = errors.Unwrap(err) {
+		if pcs := stackTracePCs(err); len(pcs) > 0 {
+			found = stackFromPCs(pcs)
+		}
+	}
+	return found, len(found) > 0
+}
+
+// stackTracePCs calls err.StackTrace() if it returns a slice of uintptr-based
+// values (pkg/errors' StackTrace is []Frame with Frame uintptr)
+func stackTracePCs(err error) []uintptr {
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return nil
+	}
+	out := m.Type().Out(0)
+	if out.Kind() != reflect.Slice || out.Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+	v := m.Call(nil)[0]
+	pcs := make([]uintptr, v.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(v.Index(i).Uint())
+	}
+	return pcs
+}
+
+// Stack variants always attach the current call stack as a "stack" attribute,
+// without enabling stack traces globally.
+
+// TraceStack logs at TRACE level with the current stack trace
+func TraceStack(msg string, args ...any) {
+	if !Enabled(LevelTrace) {
+		return
+	}
+	logWithCaller(context.Background(), LevelTrace, msg, append(args[:len(args):len(args)], "stack", captureStack())...)
+}
+
+// DebugStack logs at DEBUG level with the current stack trace
+func DebugStack(msg string, args ...any) {
+	if !Enabled(LevelDebug) {
+		return
+	}
+	logWithCaller(context.Background(), LevelDebug, msg, append(args[:len(args):len(args)], "stack", captureStack())...)
+}
+
+// InfoStack logs at INFO level with the current stack trace
+func InfoStack(msg string, args ...any) {
+	if !Enabled(LevelInfo) {
+		return
+	}
+	logWithCaller(context.Background(), LevelInfo, msg, append(args[:len(args):len(args)], "stack", captureStack())...)
+}
+
+// WarnStack logs at WARN level with the current stack trace
+func WarnStack(msg string, args ...any) {
+	if !Enabled(LevelWarn) {
+		return
+	}
+	logWithCaller(context.Background(), LevelWarn, msg, append(args[:len(args):len(args)], "stack", captureStack())...)
+}
+
+// ErrorStack logs err at ERROR level with a stack trace: the trace recorded
+// by the error itself if it has one (pkg/errors style, anywhere in its wrap
+// chain), otherwise the current call stack. err is added as an "err" attr
+// and may be nil.
+//
+//	if err := db.Ping(); err != nil {
+//	    log.ErrorStack("db unavailable", err, "host", host)
+//	}
+func ErrorStack(msg string, err error, args ...any) {
+	if !Enabled(LevelError) {
+		return
+	}
+	stack, ok := errorStack(err)
+	if !ok {
+		stack = captureStack()
+	}
+	args = args[:len(args):len(args)] // Appends must not write into the caller's array
+	if err != nil {
+		args = append(args, "err", err)
+	}
+	logWithCaller(context.Background(), LevelError, msg, append(args, "stack", stack)...)
+}
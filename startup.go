@@ -0,0 +1,21 @@
+package glogi
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// processStart approximates the process start time (package initialization)
+var processStart = time.Now()
+
+// Ready logs a standardized INFO record signalling the service is ready to accept traffic:
+//
+//	log.Ready(":8080") // ... ready addr=:8080 pid=1234 uptime=152ms ready=true
+//
+// Orchestration and health tooling can detect readiness by the ready=true attr.
+// uptime is the time from process start to the Ready call.
+func Ready(addr string, args ...any) {
+	attrs := []any{"addr", addr, "pid", os.Getpid(), "uptime", time.Since(processStart), "ready", true}
+	logWithCaller(context.Background(), LevelInfo, "ready", append(attrs, args...)...)
+}
@@ -0,0 +1,61 @@
+package glogi
+
+import (
+	"log/slog"
+	"slices"
+	"sync/atomic"
+)
+
+// levelCounts counts emitted records per standard level (index into allLevels)
+var levelCounts [7]atomic.Uint64
+
+// levelIndex returns the allLevels index of the standard level l is shown as
+func levelIndex(l slog.Level) int {
+	return slices.Index(allLevels, standardLevel(l))
+}
+
+// countRecord records an emitted record in the per-level stats
+func countRecord(l slog.Level) {
+	levelCounts[levelIndex(l)].Add(1)
+}
+
+// Stats returns how many records were emitted per level since startup (or the
+// last ResetStats), across all glogi handlers. Records filtered by level,
+// sampling or rate limits are not counted. Levels between the standard ones
+// count toward the level they are shown as.
+//
+//	if log.Stats()[log.LevelError] > 0 { ... }
+func Stats() map[slog.Level]uint64 {
+	stats := make(map[slog.Level]uint64, len(allLevels))
+	for i, l := range allLevels {
+		stats[l] = levelCounts[i].Load()
+	}
+	return stats
+}
+
+// ResetStats sets all per-level counts to zero
+func ResetStats() {
+	for i := range levelCounts {
+		levelCounts[i].Store(0)
+	}
+}
+
+// SeqKey is the attr key of the sequence number added by SetSequenceNumbers
+const SeqKey = "seq"
+
+var (
+	sequenceNumbers bool
+	seqCounter      atomic.Uint64
+)
+
+// SetSequenceNumbers adds seq=N to every emitted record, where N increases by
+// one per record across all glogi handlers. Numbers are taken atomically when
+// the record is admitted, so they give the emission order even when
+// concurrent writes reach the destination out of order. Off by default.
+func SetSequenceNumbers(on bool) { sequenceNumbers = on }
+
+// addSequence appends the next sequence number to r
+func addSequence(r *slog.Record) {
+	*r = r.Clone() // Don't append into a backing array shared with the caller's copy
+	r.AddAttrs(slog.Uint64(SeqKey, seqCounter.Add(1)))
+}
@@ -0,0 +1,86 @@
+package glogi
+
+import (
+	"log/slog"
+	"strings"
+)
+
+// SubsystemKey is the attr key naming a record's subsystem for SetLevelFor
+const SubsystemKey = "subsystem"
+
+// subsystemLevels holds the per-subsystem minimum levels (LOG_LEVELS)
+var subsystemLevels map[string]slog.Level
+
+// SetLevelFor sets the minimum level of one subsystem, overriding the global
+// level for its records. A logger belongs to subsystem name when its first
+// group is name (WithGroup("db")) or it has a subsystem=name attr (With), so
+// it can be more verbose than the rest of the app, or quieter:
+//
+//	log.SetLevelFor("db", log.LevelDebug)
+//	db := log.With("subsystem", "db") // or log.SLogger().WithGroup("db")
+//	db.Debug("query", "sql", q)       // emitted at global INFO
+//
+// A subsystem attr passed at the call site can only drop records the level
+// lets through. LOG_LEVELS=db=debug,http=warn sets levels from env.
+func SetLevelFor(name string, level slog.Level) {
+	if name = strings.TrimSpace(name); name == "" {
+		return
+	}
+	levels := make(map[string]slog.Level, len(subsystemLevels)+1)
+	for k, v := range subsystemLevels {
+		levels[k] = v
+	}
+	levels[name] = level
+	subsystemLevels = levels
+}
+
+// parseSubsystemLevels sets subsystem levels from a LOG_LEVELS value
+// (name=level pairs separated by commas); malformed pairs are skipped
+func parseSubsystemLevels(s string) {
+	for _, pair := range strings.Split(s, ",") {
+		name, lvl, ok := strings.Cut(pair, "=")
+		if ok && strings.TrimSpace(lvl) != "" {
+			SetLevelFor(name, parseLevel(lvl))
+		}
+	}
+}
+
+// subsystemLevel returns the level set for the subsystem of a handler, given
+// its first group and bound attrs, if any
+func subsystemLevel(firstGroup string, handlerAttrs func() []slog.Attr) (slog.Level, bool) {
+	levels := subsystemLevels
+	if levels == nil {
+		return 0, false
+	}
+	if l, ok := levels[firstGroup]; ok && firstGroup != "" {
+		return l, true
+	}
+	attrs := handlerAttrs()
+	for i := len(attrs) - 1; i >= 0; i-- {
+		if attrs[i].Key == SubsystemKey {
+			l, ok := levels[attrs[i].Value.Resolve().String()]
+			return l, ok
+		}
+	}
+	return 0, false
+}
+
+// subsystemAdmits reports whether r passes the level of the subsystem named
+// by its own subsystem attr (true if it has none)
+func subsystemAdmits(r slog.Record) bool {
+	levels := subsystemLevels
+	if levels == nil {
+		return true
+	}
+	admit := true
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key != SubsystemKey {
+			return true
+		}
+		if l, ok := levels[a.Value.Resolve().String()]; ok {
+			admit = r.Level >= l
+		}
+		return false
+	})
+	return admit
+}
@@ -0,0 +1,68 @@
+//go:build !windows && !plan9
+
+package glogi
+
+import (
+	"io"
+	"log/slog"
+	"log/syslog"
+	"strings"
+)
+
+// syslogWriter writes records to syslog with a severity derived from their level
+type syslogWriter struct {
+	w *syslog.Writer
+}
+
+// NewSyslogWriter connects to a syslog daemon, for use with SetOutput or
+// AddOutput. network and addr are as for net.Dial ("udp", "localhost:514");
+// both empty connect to the local daemon. tag defaults to the program name.
+// Each record gets the severity of its level:
+//
+//	TRACE, DEBUG -> debug
+//	INFO         -> info
+//	WARN         -> warning
+//	ERROR        -> err
+//	FATAL, PANIC -> crit
+//
+// Syslog adds its own timestamp, so SetTimeFormat("") avoids a second one.
+// The returned writer implements io.Closer. Not available on Windows and Plan 9.
+func NewSyslogWriter(network, addr, tag string) (io.Writer, error) {
+	w, err := syslog.Dial(network, addr, syslog.LOG_INFO|syslog.LOG_USER, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &syslogWriter{w: w}, nil
+}
+
+// Write sends p at info severity (output that carries no level)
+func (s *syslogWriter) Write(p []byte) (int, error) {
+	return s.writeLevel(LevelInfo, p)
+}
+
+// writeLevel sends a record with the severity of level l
+func (s *syslogWriter) writeLevel(l slog.Level, p []byte) (int, error) {
+	msg := strings.TrimRight(string(p), "\r\n")
+	var err error
+	switch {
+	case l < LevelInfo:
+		err = s.w.Debug(msg)
+	case l < LevelWarn:
+		err = s.w.Info(msg)
+	case l < LevelError:
+		err = s.w.Warning(msg)
+	case l < LevelFatal:
+		err = s.w.Err(msg)
+	default:
+		err = s.w.Crit(msg)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the connection to the syslog daemon
+func (s *syslogWriter) Close() error {
+	return s.w.Close()
+}
@@ -0,0 +1,13 @@
+//go:build windows || plan9
+
+package glogi
+
+import (
+	"errors"
+	"io"
+)
+
+// NewSyslogWriter is not supported on this platform (log/syslog is unix-only)
+func NewSyslogWriter(network, addr, tag string) (io.Writer, error) {
+	return nil, errors.New("glogi: syslog is not supported on this platform")
+}
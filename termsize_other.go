@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd && !windows
+
+package glogi
+
+import "os"
+
+// terminalColumns is not supported on this platform; COLUMNS is used instead
+func terminalColumns(f *os.File) int { return 0 }
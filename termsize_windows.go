@@ -0,0 +1,30 @@
+//go:build windows
+
+package glogi
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var procGetConsoleScreenBufferInfo = syscall.NewLazyDLL("kernel32.dll").NewProc("GetConsoleScreenBufferInfo")
+
+// consoleScreenBufferInfo mirrors CONSOLE_SCREEN_BUFFER_INFO
+type consoleScreenBufferInfo struct {
+	size           [2]int16
+	cursorPosition [2]int16
+	attributes     uint16
+	window         [4]int16 // Left, top, right, bottom
+	maxWindowSize  [2]int16
+}
+
+// terminalColumns returns the width of the console f is connected to, or 0
+func terminalColumns(f *os.File) int {
+	var info consoleScreenBufferInfo
+	r, _, _ := procGetConsoleScreenBufferInfo.Call(f.Fd(), uintptr(unsafe.Pointer(&info)))
+	if r == 0 {
+		return 0
+	}
+	return int(info.window[2]-info.window[0]) + 1
+}
@@ -0,0 +1,68 @@
+package glogi
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"time"
+)
+
+var (
+	timedLevel          = LevelInfo  // Level used by Timed
+	timedStart          = false      // Whether Timed also logs a start line
+	outcomeFailureLevel = LevelError // Level used by Outcome for failures
+)
+
+// SetTimedLevel sets the level used by Timed (default INFO)
+func SetTimedLevel(l slog.Level) { timedLevel = l }
+
+// SetTimedStart enables an additional "<name> started" line when Timed is called
+func SetTimedStart(enabled bool) { timedStart = enabled }
+
+// Timed measures a function's execution time. The returned func logs name with
+// the elapsed duration attr:
+//
+//	func sync() {
+//	    defer log.Timed("sync", "shard", 3)() // ... sync shard=3 duration=1.2s
+//	    ...
+//	}
+//
+// Both records are attributed to the Timed call site. When the level is
+// disabled, Timed returns a no-op and does not read the clock.
+func Timed(name string, args ...any) func() {
+	lvl := timedLevel
+	if !Enabled(lvl) {
+		return func() {}
+	}
+
+	var pcs [1]uintptr
+	if captureSource(lvl) {
+		runtime.Callers(2+callerSkip, pcs[:]) // skip: Callers, Timed
+	}
+
+	if timedStart {
+		logAtPC(lvl, pcs[0], name+" started", args...)
+	}
+	start := time.Now()
+	return func() {
+		attrs := append(args[:len(args):len(args)], "duration", time.Since(start))
+		logAtPC(lvl, pcs[0], name, attrs...)
+	}
+}
+
+// SetOutcomeFailureLevel sets the level Outcome uses for failures (default ERROR),
+// e.g. LevelWarn for operations whose failure is handled by the caller
+func SetOutcomeFailureLevel(l slog.Level) { outcomeFailureLevel = l }
+
+// Outcome logs the result of a (possibly retried) operation with standardized attrs:
+//
+//	log.Outcome("fetch_prices", err, attempts, time.Since(start))
+//	// success: INFO  fetch_prices outcome=success attempts=2 duration=340ms
+//	// failure: ERROR fetch_prices outcome=failure attempts=5 duration=2.1s err=timeout
+func Outcome(name string, err error, attempts int, dur time.Duration) {
+	if err == nil {
+		logWithCaller(context.Background(), LevelInfo, name, "outcome", "success", "attempts", attempts, "duration", dur)
+		return
+	}
+	logWithCaller(context.Background(), outcomeFailureLevel, name, "outcome", "failure", "attempts", attempts, "duration", dur, "err", err)
+}
@@ -0,0 +1,24 @@
+package glogi
+
+import (
+	"io"
+	"os"
+)
+
+// isTerminal reports whether w is an *os.File connected to a character device
+// (a terminal) that can display ANSI colors
+func isTerminal(w io.Writer) bool {
+	if a, ok := w.(*asyncWriter); ok {
+		w = a.out // Colors follow the final destination
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	// On Windows the console must also accept ANSI escapes
+	return fi.Mode()&os.ModeCharDevice != 0 && enableVirtualTerminal(f)
+}